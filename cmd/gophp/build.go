@@ -1,97 +1,325 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
+	"github.com/wuwuseo/gophpffi/generator"
 )
 
 var buildCmd = &cobra.Command{
-	Use:   "build [source.go]",
+	Use:   "build [source.go|dir|import-path]",
 	Short: "构建 Go 共享库",
-	Long: `从源文件构建 Go 共享库 (.dll/.so/.dylib)。
+	Long: `从源文件或整个包构建 Go 共享库 (.dll/.so/.dylib)。
 	
-库文件将被放置在 dist/lib/ 目录中。`,
+参数可以是单个 .go 文件、包目录或导入路径，包内所有文件
+会被编译进同一个共享库。
+库文件将被放置在 .gophp.yaml 的 output.lib_dir（默认 dist/lib/）中。
+
+默认只构建当前平台；使用 --target（可重复）或 .gophp.yaml 中的
+targets 列表进行交叉编译。go build 的参数、标签与环境变量来自
+构建配置档（--profile，默认为 build.profile 或 dev）。多个目标并行构建（--jobs 或配置中的 jobs
+控制并发数）；输入未变化的目标会根据 output.dir 中的
+.gophp-build-cache.json 跳过，使用 --force 可强制重新构建。
+构建完成后会读取共享库的符号表，检查生成器解析得到的导出函数与
+PHP 服务类调用的符号是否都已导出（--no-verify 跳过）。
+
+未指定参数时构建 .gophp.yaml 中的全部服务，可用 --service 选择其中一部分。`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runBuild,
 }
 
+var (
+	// buildTargetFlags 是 --target 指定的目标平台（os/arch）
+	buildTargetFlags []string
+	// buildJobs 是 --jobs 指定的并行构建数
+	buildJobs int
+	// forceBuild 为 true 时忽略构建缓存
+	forceBuild bool
+)
+
 func init() {
+	buildCmd.Flags().StringVar(&buildTags, "tags", "", "逗号分隔的构建标签")
+	buildCmd.Flags().StringVar(&buildProfile, "profile", "", "构建配置档（dev、release 或 .gophp.yaml 中 build.profiles 定义的名称）")
+	buildCmd.Flags().StringSliceVar(&buildTargetFlags, "target", nil, "目标平台（os/arch，可重复或以逗号分隔），例如 linux/arm64")
+	buildCmd.Flags().IntVarP(&buildJobs, "jobs", "j", 0, "并行构建的目标数（默认使用配置中的 jobs 或 CPU 核数）")
+	buildCmd.Flags().BoolVar(&forceBuild, "force", false, "忽略构建缓存，重新构建所有目标")
+	buildCmd.Flags().BoolVar(&noVerify, "no-verify", false, "跳过构建后的导出符号检查")
+	buildCmd.Flags().StringSliceVar(&serviceFilter, "service", nil, "只处理 .gophp.yaml 中指定的服务（可重复或以逗号分隔）")
 	rootCmd.AddCommand(buildCmd)
 }
 
+// targetResult 记录单个服务在单个目标上的构建结果
+type targetResult struct {
+	Service string
+	Target  BuildTarget
+	Library string
+	Cached  bool // 输入未变化，沿用上次构建的产物
+	Err     error
+}
+
 func runBuild(cmd *cobra.Command, args []string) error {
-	// Load config or use args
-	config, err := loadConfig()
-	if err != nil && len(args) == 0 {
-		return fmt.Errorf("未指定源文件且找不到 .gophp.yaml")
+	proj, err := resolveProject(args)
+	if err != nil {
+		return err
 	}
 
-	var sourceFile, serviceName string
-	if len(args) > 0 {
-		sourceFile = args[0]
-		serviceName = filepath.Base(sourceFile)
-		serviceName = serviceName[:len(serviceName)-3] // Remove .go
-	} else {
-		sourceFile = config.Source
-		serviceName = config.Service
+	jobs := buildJobs
+	if jobs <= 0 {
+		jobs = proj.Jobs
 	}
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	profileName, profile, err := resolveProfile(buildProfile, proj.Build)
+	if err != nil {
+		return err
+	}
+	git := newGitInfo(proj.Build.Version)
 
 	fmt.Println("=== 正在构建 Go 共享库 ===")
-	fmt.Printf("源文件：%s\n", sourceFile)
-	fmt.Printf("服务名：%s\n\n", serviceName)
+	fmt.Printf("配置档：%s\n", profileName)
+
+	var (
+		results []targetResult
+		pending []pendingBuild
+		libDirs []string
+		caches  = make(map[string]*buildCache) // 按输出目录区分的构建缓存
+	)
+	// 先确定每个目标是否需要构建，全部检查完缓存并确定 results 的长度后再启动构建
+	for _, src := range proj.Services {
+		targets, err := resolveTargets(buildTargetFlags, src.Targets)
+		if err != nil {
+			return fmt.Errorf("%s：%w", src.Service, err)
+		}
 
-	// Create output directories
-	distDir := "dist"
-	libDir := filepath.Join(distDir, "lib")
-	if err := os.MkdirAll(libDir, 0755); err != nil {
-		return fmt.Errorf("创建目录失败：%w", err)
+		fmt.Printf("源码：%s\n", src.Source)
+		fmt.Printf("服务名：%s\n\n", src.Service)
+
+		// Create output directories
+		for _, dir := range []string{src.OutputDir, src.LibDir} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("创建目录失败：%w", err)
+			}
+		}
+		if !slices.Contains(libDirs, src.LibDir) {
+			libDirs = append(libDirs, src.LibDir)
+		}
+
+		cache := caches[src.OutputDir]
+		if cache == nil {
+			cache = loadBuildCache(src.OutputDir)
+			caches[src.OutputDir] = cache
+		}
+		// 用于构建后检查的导出符号，解析失败时跳过检查
+		var expected *generator.Result
+		if !noVerify {
+			result, err := generator.Inspect(generatorOptions(src, profile.Tags))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "警告：无法解析 %s 的导出函数，跳过导出符号检查：%v\n\n", src.Service, err)
+			} else {
+				expected = &result
+			}
+		}
+
+		// 构建输入哈希按标签与目标环境（GOOS/GOARCH 决定参与构建的文件）计算，失败时不使用缓存
+		inputsByEnv := make(map[string]string)
+		inputsHash := func(tags string, env []string) string {
+			key := tags + "\x00" + strings.Join(env, "\x00")
+			inputs, ok := inputsByEnv[key]
+			if !ok {
+				var err error
+				if inputs, err = sourceInputsHash(src, tags, env); err != nil {
+					fmt.Fprintf(os.Stderr, "警告：无法计算 %s 的构建输入哈希，本次不使用缓存：%v\n\n", src.Service, err)
+				}
+				inputsByEnv[key] = inputs
+			}
+			return inputs
+		}
+
+		for _, target := range targets {
+			targetProfile, err := profile.merge(target.Overrides).expand(buildVars{
+				Service: src.Service,
+				OS:      target.OS,
+				Arch:    target.Arch,
+				Profile: profileName,
+				git:     git,
+			})
+			if err != nil {
+				return fmt.Errorf("%s %s：%w", src.Service, target.Platform(), err)
+			}
+
+			outputPath := displayPath(libraryPath(src.LibDir, src.Service, target))
+			key := cacheKey(src.Service, target)
+			hash := ""
+			if inputs := inputsHash(targetProfile.tagList(), buildEnv(targetProfile.envList(), target)); inputs != "" {
+				hash = targetHash(inputs, target, outputPath, targetProfile)
+			}
+			if hash != "" && !forceBuild {
+				if library, ok := cache.upToDate(key, hash); ok {
+					fmt.Printf("%s %s 输入未变化，跳过构建\n\n", src.Service, target.Platform())
+					results = append(results, targetResult{Service: src.Service, Target: target, Library: library, Cached: true})
+					continue
+				}
+			}
+
+			pending = append(pending, pendingBuild{
+				index:    len(results),
+				src:      src,
+				target:   target,
+				profile:  targetProfile,
+				output:   outputPath,
+				expected: expected,
+				cache:    cache,
+				key:      key,
+				hash:     hash,
+			})
+			results = append(results, targetResult{Service: src.Service, Target: target})
+		}
 	}
 
-	// Get platform info
-	goos := runtime.GOOS
-	goarch := runtime.GOARCH
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex // 保护 caches 与终端输出；每个构建只写入 results 中属于自己的元素
+		sem = make(chan struct{}, jobs)
+	)
+	for _, job := range pending {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// 每个目标的输出先写入缓冲区，完成后整体打印，避免并行构建时日志交错
+			var log bytes.Buffer
+			library, err := buildTarget(job.src, job.target, job.profile, job.output, job.expected, &log)
+			results[job.index].Library, results[job.index].Err = library, err
 
-	// Determine extension
-	var ext string
-	switch goos {
-	case "windows":
-		ext = "dll"
-	case "darwin":
-		ext = "dylib"
-	default:
-		ext = "so"
+			mu.Lock()
+			defer mu.Unlock()
+			os.Stdout.Write(log.Bytes())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "✗ %s %s %v\n\n", job.src.Service, job.target.Platform(), err)
+				delete(job.cache.Targets, job.key)
+			} else if job.hash != "" {
+				job.cache.Targets[job.key] = cacheEntry{Hash: job.hash, Library: library}
+			}
+		}()
 	}
+	wg.Wait()
 
-	// Build output path
-	outputName := fmt.Sprintf("%s-%s-%s.%s", serviceName, goos, goarch, ext)
-	outputPath := filepath.Join(libDir, outputName)
+	for dir, cache := range caches {
+		if err := cache.save(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "警告：写入构建缓存失败：%v\n", err)
+		}
+	}
 
-	fmt.Printf("正在为 %s-%s 构建...\n", goos, goarch)
-	fmt.Printf("输出：%s\n\n", outputPath)
+	return printBuildSummary(results, libDirs)
+}
+
+// pendingBuild 是需要实际构建的目标，index 为其在构建结果中的位置
+type pendingBuild struct {
+	index    int
+	src      *serviceSource
+	target   BuildTarget
+	profile  BuildProfile
+	output   string
+	expected *generator.Result
+	cache    *buildCache
+	key      string
+	hash     string
+}
+
+// libraryPath 返回目标平台的共享库输出路径
+func libraryPath(libDir, service string, target BuildTarget) string {
+	outputName := fmt.Sprintf("%s-%s-%s.%s", service, target.OS, target.Arch, libExtension(target.OS))
+	return filepath.Join(libDir, outputName)
+}
+
+// buildTarget 为单个目标平台构建共享库并生成 FFI 头文件，返回库文件路径
+// profile 为已展开的构建配置档；expected 不为 nil 时检查共享库是否导出了其中的符号；
+// 构建日志与 go build 的输出都写入 out
+func buildTarget(src *serviceSource, target BuildTarget, profile BuildProfile, outputPath string, expected *generator.Result, out io.Writer) (string, error) {
+	fmt.Fprintf(out, "正在为 %s-%s 构建...\n", target.OS, target.Arch)
+	fmt.Fprintf(out, "输出：%s\n", outputPath)
+	if target.CC != "" {
+		fmt.Fprintf(out, "CC：%s\n", target.CC)
+	}
+	if args := profile.goBuildArgs(); len(args) > 0 {
+		fmt.Fprintf(out, "参数：%s\n", strings.Join(args, " "))
+	}
+	if env := profile.envList(); len(env) > 0 {
+		fmt.Fprintf(out, "环境变量：%s\n", strings.Join(env, " "))
+	}
+	fmt.Fprintln(out)
 
 	// Build command
-	buildCmd := exec.Command("go", "build",
-		"-buildmode=c-shared",
-		"-o", outputPath,
-		sourceFile,
-	)
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
+	buildArgs := []string{"build", "-buildmode=c-shared", "-o", outputPath}
+	buildArgs = append(buildArgs, profile.goBuildArgs()...)
+	buildArgs = append(buildArgs, buildTargets(src.Source, src.Service)...)
+	buildCmd := exec.Command("go", buildArgs...)
+	buildCmd.Env = buildEnv(append(os.Environ(), profile.envList()...), target)
+	buildCmd.Stdout = out
+	buildCmd.Stderr = out
 
 	if err := buildCmd.Run(); err != nil {
-		return fmt.Errorf("构建失败：%w", err)
+		return "", fmt.Errorf("构建失败：%w", err)
+	}
+
+	if expected != nil {
+		warnings, err := verifyExports(outputPath, target.OS, expected.Symbols, expected.Wrapped, expected.PHPFile)
+		for _, warning := range warnings {
+			fmt.Fprintf(out, "警告：%s\n", warning)
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(out, "✓ 导出符号检查通过（%d 个）\n", len(expected.Symbols))
+	}
+
+	// 将 cgo 头文件清理为 PHP FFI 可直接加载的 .ffi.h
+	headerPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".h"
+	if _, err := writeFFIHeader(headerPath, outputPath, src.Service); err != nil {
+		return "", fmt.Errorf("生成 FFI 头文件失败：%w", err)
 	}
 
-	fmt.Println("\n✓ 库文件已生成到 dist/lib/")
+	return outputPath, nil
+}
+
+// printBuildSummary 输出每个目标的构建结果，存在失败的目标时返回错误
+func printBuildSummary(results []targetResult, libDirs []string) error {
+	failed := 0
+	fmt.Println("=== 构建摘要 ===")
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("✗ %-12s %-16s %v\n", r.Service, r.Target.Platform(), r.Err)
+			continue
+		}
+		if r.Cached {
+			fmt.Printf("✓ %-12s %-16s %s（缓存）\n", r.Service, r.Target.Platform(), r.Library)
+			continue
+		}
+		fmt.Printf("✓ %-12s %-16s %s\n", r.Service, r.Target.Platform(), r.Library)
+	}
 	fmt.Println()
-	fmt.Println("=== 构建完成！===")
-	fmt.Printf("库文件：%s\n", outputPath)
-	fmt.Printf("头文件：%s\n", outputPath[:len(outputPath)-len(ext)]+"h")
 
+	if failed > 0 {
+		return fmt.Errorf("%d/%d 个目标构建失败", failed, len(results))
+	}
+	fmt.Println("=== 构建完成！===")
+	for _, dir := range libDirs {
+		fmt.Printf("库文件与头文件（.h / .ffi.h）位于 %s\n", displayPath(dir))
+	}
 	return nil
 }
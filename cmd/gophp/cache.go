@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// buildCacheFile 是记录上次成功构建输入哈希的缓存文件（位于输出目录下）
+const buildCacheFile = ".gophp-build-cache.json"
+
+// buildCache 记录每个服务与目标上次成功构建时的输入哈希
+type buildCache struct {
+	Targets map[string]cacheEntry `json:"targets"`
+}
+
+// cacheEntry 是单个目标的缓存记录
+type cacheEntry struct {
+	Hash    string `json:"hash"`
+	Library string `json:"library"`
+}
+
+// loadBuildCache 读取缓存文件，文件不存在或损坏时返回空缓存
+func loadBuildCache(dir string) *buildCache {
+	cache := &buildCache{Targets: make(map[string]cacheEntry)}
+	data, err := os.ReadFile(filepath.Join(dir, buildCacheFile))
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Targets == nil {
+		return &buildCache{Targets: make(map[string]cacheEntry)}
+	}
+	return cache
+}
+
+// save 写回缓存文件
+func (c *buildCache) save(dir string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, buildCacheFile), append(data, '\n'), 0644)
+}
+
+// cacheKey 返回服务在目标平台上的缓存键（同一输出目录可能包含多个服务）
+func cacheKey(service string, target BuildTarget) string {
+	return service + " " + target.Platform()
+}
+
+// upToDate 判断目标的输入是否与上次成功构建一致且产物仍然存在
+func (c *buildCache) upToDate(key, hash string) (string, bool) {
+	entry, ok := c.Targets[key]
+	if !ok || entry.Hash != hash {
+		return "", false
+	}
+	header := strings.TrimSuffix(entry.Library, filepath.Ext(entry.Library)) + ".ffi.h"
+	for _, path := range []string{entry.Library, header} {
+		if _, err := os.Stat(path); err != nil {
+			return "", false
+		}
+	}
+	return entry.Library, true
+}
+
+// listedPackage 是 go list -json 输出中用于计算构建输入的字段
+type listedPackage struct {
+	Dir        string
+	Standard   bool
+	GoFiles    []string
+	CgoFiles   []string
+	CFiles     []string
+	CXXFiles   []string
+	MFiles     []string
+	HFiles     []string
+	SFiles     []string
+	SysoFiles  []string
+	EmbedFiles []string
+}
+
+// sourceInputsHash 计算构建输入的哈希：
+// 源码包及其非标准库依赖中参与构建的文件、go.mod/go.sum 以及 Go 工具链版本；
+// tags 与 env（目标平台的 GOOS/GOARCH 等，追加在当前环境之后）决定参与构建的依赖与文件
+func sourceInputsHash(src *serviceSource, tags string, env []string) (string, error) {
+	listArgs := []string{"list", "-deps", "-json"}
+	if tags != "" {
+		listArgs = append(listArgs, "-tags", tags)
+	}
+	listArgs = append(listArgs, buildTargets(src.Source, src.Service)...)
+	listCmd := exec.Command("go", listArgs...)
+	listCmd.Env = append(os.Environ(), env...)
+	out, err := listCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("列出依赖失败：%w", err)
+	}
+	envCmd := exec.Command("go", "env", "GOVERSION", "GOMOD")
+	envCmd.Env = listCmd.Env
+	version, err := envCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("获取 Go 环境失败：%w", err)
+	}
+
+	h := sha256.New()
+	envLines := strings.Split(strings.TrimSpace(string(version)), "\n")
+	fmt.Fprintf(h, "go %s\n", envLines[0])
+
+	var files []string
+	if len(envLines) > 1 && envLines[1] != "" && envLines[1] != os.DevNull {
+		modDir := filepath.Dir(envLines[1])
+		files = append(files, filepath.Join(modDir, "go.mod"), filepath.Join(modDir, "go.sum"))
+	}
+	seen := make(map[string]bool)
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg listedPackage
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("解析 go list 输出失败：%w", err)
+		}
+		if pkg.Standard {
+			continue
+		}
+		// 只计入目标平台与构建标签下实际编译的文件
+		for _, list := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.CXXFiles, pkg.MFiles,
+			pkg.HFiles, pkg.SFiles, pkg.SysoFiles, pkg.EmbedFiles} {
+			for _, name := range list {
+				file := filepath.Join(pkg.Dir, name)
+				if !seen[file] {
+					seen[file] = true
+					files = append(files, file)
+				}
+			}
+		}
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "file %s\n", file)
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// targetHash 将源码输入哈希与目标相关的构建参数组合为目标的缓存键
+// profile 为已展开的配置档，其参数与环境变量变化时会重新构建；
+// 输出路径按绝对路径计算，项目目录移动后 .ffi.h 中的 FFI_LIB 会随之更新
+func targetHash(inputs string, target BuildTarget, outputPath string, profile BuildProfile) string {
+	if abs, err := filepath.Abs(outputPath); err == nil {
+		outputPath = abs
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "inputs %s\nplatform %s\ncc %s\ncxx %s\noutput %s\n",
+		inputs, target.Platform(), target.CC, target.CXX, outputPath)
+	for _, arg := range profile.goBuildArgs() {
+		fmt.Fprintf(h, "arg %s\n", arg)
+	}
+	for _, env := range profile.envList() {
+		fmt.Fprintf(h, "env %s\n", env)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceInputsHashUsesTargetEnv(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":          "module example.com/calc\n\ngo 1.21\n",
+		"calc.go":         "package main\n\nimport \"C\"\n\n//export Add\nfunc Add(a, b int) int { return a + b }\n\nfunc main() {}\n",
+		"calc_windows.go": "package main\n\nconst platform = \"windows\"\n",
+		"calc_linux.go":   "package main\n\nconst platform = \"linux\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+
+	src := &serviceSource{Source: ".", Service: "calc"}
+	linux := BuildTarget{OS: "linux", Arch: "amd64"}
+	windows := BuildTarget{OS: "windows", Arch: "amd64"}
+	hash := func(target BuildTarget) string {
+		t.Helper()
+		h, err := sourceInputsHash(src, "", buildEnv(nil, target))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	linuxBefore, windowsBefore := hash(linux), hash(windows)
+	if linuxBefore == windowsBefore {
+		t.Fatal("linux and windows targets share an inputs hash")
+	}
+
+	// 只有 windows 目标构建 calc_windows.go
+	if err := os.WriteFile(filepath.Join(dir, "calc_windows.go"), []byte("package main\n\nconst platform = \"win\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := hash(linux); got != linuxBefore {
+		t.Error("editing calc_windows.go changed the linux inputs hash")
+	}
+	if got := hash(windows); got == windowsBefore {
+		t.Error("editing calc_windows.go did not change the windows inputs hash")
+	}
+}
@@ -3,23 +3,77 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/wuwuseo/gophpffi/generator"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the .gophp.yaml configuration
+// 顶层的 service/source 描述单个服务；多服务项目使用 services 列表，
+// 此时顶层的 json、runtime、preload、targets 与 output 作为各服务的默认值
 type Config struct {
-	Service string `yaml:"service"`
-	Source  string `yaml:"source"`
-	Output  struct {
-		Dir    string `yaml:"dir"`
-		LibDir string `yaml:"lib_dir"`
-	} `yaml:"output"`
+	Service   string          `yaml:"service"`
+	Source    string          `yaml:"source"`
+	Namespace string          `yaml:"namespace"`  // PHP 命名空间模板，默认为 app\{{.Snake}}\service
+	Class     string          `yaml:"class"`      // PHP 服务类名模板，默认为 {{.Pascal}}Service
+	BaseClass string          `yaml:"base_class"` // 服务类的父类（完全限定名）
+	JSON      bool            `yaml:"json"`       // 通过 JSON 桥接 map/结构体参数与返回值
+	Runtime   string          `yaml:"runtime"`    // PHP 运行时：library（默认）或 embedded
+	Preload   bool            `yaml:"preload"`    // 生成 opcache 预加载脚本（需要 embedded 运行时）
+	Targets   []BuildTarget   `yaml:"targets"`    // 交叉编译目标，为空时只构建当前平台
+	Jobs      int             `yaml:"jobs"`       // 并行构建的目标数，为 0 时使用 CPU 核数
+	Build     BuildConfig     `yaml:"build"`      // go build 参数的配置档
+	Naming    NamingConfig    `yaml:"naming"`
+	Output    OutputConfig    `yaml:"output"`
+	Services  []ServiceConfig `yaml:"services"` // 多服务项目中的服务列表
 }
 
+// OutputConfig 描述 PHP 文件与共享库的输出目录
+type OutputConfig struct {
+	Dir     string `yaml:"dir"`
+	LibDir  string `yaml:"lib_dir"`
+	PHPFile string `yaml:"php_file"` // PHP 服务类文件路径模板（相对配置文件所在目录），默认为 <dir>/<class>.php
+}
+
+// NamingConfig 描述生成的 PHP 名称的命名策略（取值见 generator.Naming）
+type NamingConfig struct {
+	Methods    string   `yaml:"methods"`    // keep、camel 或 snake
+	Classes    string   `yaml:"classes"`    // keep 或 pascal
+	Namespaces string   `yaml:"namespaces"` // keep、pascal、snake 或 lower
+	Acronyms   []string `yaml:"acronyms"`   // 额外的缩写词，例如 [SKU, OAuth]
+}
+
+// ServiceConfig 描述 services 列表中的一个服务，未设置的字段沿用顶层配置
+type ServiceConfig struct {
+	Name      string        `yaml:"name"`
+	Source    string        `yaml:"source"`
+	Namespace string        `yaml:"namespace"`
+	Class     string        `yaml:"class"`
+	BaseClass string        `yaml:"base_class"`
+	JSON      *bool         `yaml:"json"`
+	Runtime   string        `yaml:"runtime"`
+	Preload   *bool         `yaml:"preload"`
+	Targets   []BuildTarget `yaml:"targets"`
+	Naming    NamingConfig  `yaml:"naming"` // 未设置的策略沿用顶层 naming
+	Output    OutputConfig  `yaml:"output"` // 只设置 dir 时 lib_dir 默认为 <dir>/lib
+}
+
+const (
+	// configFile 是项目配置文件名
+	configFile = ".gophp.yaml"
+	// defaultOutputDir 与 defaultLibDir 是未配置 output 时的输出位置
+	defaultOutputDir = "dist"
+	defaultLibDir    = "dist/lib"
+)
+
 // loadConfig loads the .gophp.yaml configuration file
+// output.dir 与 output.lib_dir 中的相对路径会基于配置文件所在目录解析为绝对路径，
+// 返回的 Services 已合并顶层默认值；只配置了顶层 service/source 时视为只有一个服务，
+// 顶层 service/source 与 services 同时存在时返回错误
 func loadConfig() (*Config, error) {
-	data, err := os.ReadFile(".gophp.yaml")
+	data, err := os.ReadFile(configFile)
 	if err != nil {
 		return nil, fmt.Errorf("读取 .gophp.yaml 失败：%w", err)
 	}
@@ -31,11 +85,121 @@ func loadConfig() (*Config, error) {
 
 	// Set defaults
 	if config.Output.Dir == "" {
-		config.Output.Dir = "dist"
+		config.Output.Dir = defaultOutputDir
 	}
 	if config.Output.LibDir == "" {
-		config.Output.LibDir = "dist/lib"
+		config.Output.LibDir = defaultLibDir
+	}
+
+	configDir, err := filepath.Abs(filepath.Dir(configFile))
+	if err != nil {
+		return nil, fmt.Errorf("获取配置文件目录失败：%w", err)
+	}
+	config.Output.Dir = resolvePath(configDir, config.Output.Dir)
+	config.Output.LibDir = resolvePath(configDir, config.Output.LibDir)
+
+	if len(config.Services) > 0 && (config.Service != "" || config.Source != "") {
+		// 顶层服务不会被处理，直接报错以免被静默忽略
+		return nil, fmt.Errorf(".gophp.yaml 中顶层的 service/source 不能与 services 同时使用，请将该服务移到 services 列表中")
+	}
+	if len(config.Services) == 0 {
+		if config.Source == "" {
+			return nil, fmt.Errorf(".gophp.yaml 中未配置 source 或 services")
+		}
+		config.Services = []ServiceConfig{{
+			Name:   config.Service,
+			Source: config.Source,
+		}}
+	}
+
+	seen := make(map[string]bool)
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if svc.Source == "" {
+			return nil, fmt.Errorf(".gophp.yaml 中第 %d 个服务未配置 source", i+1)
+		}
+		if svc.Name == "" {
+			svc.Name = serviceNameFromSource(svc.Source)
+		}
+		if seen[svc.Name] {
+			return nil, fmt.Errorf(".gophp.yaml 中服务 %s 重复", svc.Name)
+		}
+		seen[svc.Name] = true
+
+		if svc.Namespace == "" {
+			svc.Namespace = config.Namespace
+		}
+		if svc.Class == "" {
+			svc.Class = config.Class
+		}
+		if svc.BaseClass == "" {
+			svc.BaseClass = config.BaseClass
+		}
+		if svc.JSON == nil {
+			svc.JSON = &config.JSON
+		}
+		if svc.Runtime == "" {
+			svc.Runtime = config.Runtime
+		}
+		if svc.Preload == nil {
+			svc.Preload = &config.Preload
+		}
+		if len(svc.Targets) == 0 {
+			svc.Targets = config.Targets
+		}
+		if svc.Naming.Methods == "" {
+			svc.Naming.Methods = config.Naming.Methods
+		}
+		if svc.Naming.Classes == "" {
+			svc.Naming.Classes = config.Naming.Classes
+		}
+		if svc.Naming.Namespaces == "" {
+			svc.Naming.Namespaces = config.Naming.Namespaces
+		}
+		svc.Naming.Acronyms = append(append([]string{}, config.Naming.Acronyms...), svc.Naming.Acronyms...)
+		switch {
+		case svc.Output.Dir == "":
+			svc.Output.Dir = config.Output.Dir
+			if svc.Output.LibDir == "" {
+				svc.Output.LibDir = config.Output.LibDir
+			}
+		case svc.Output.LibDir == "":
+			svc.Output.LibDir = filepath.Join(svc.Output.Dir, "lib")
+		}
+		svc.Output.Dir = resolvePath(configDir, svc.Output.Dir)
+		svc.Output.LibDir = resolvePath(configDir, svc.Output.LibDir)
+		if svc.Output.PHPFile == "" {
+			svc.Output.PHPFile = config.Output.PHPFile
+		}
+		if svc.Output.PHPFile != "" {
+			phpFile, err := generator.ExpandName(svc.Output.PHPFile, svc.Name)
+			if err != nil {
+				return nil, fmt.Errorf(".gophp.yaml 中服务 %s 的 php_file 无效：%w", svc.Name, err)
+			}
+			svc.Output.PHPFile = resolvePath(configDir, phpFile)
+		}
 	}
 
 	return &config, nil
 }
+
+// resolvePath 将相对路径解析为基于 base 的绝对路径
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// displayPath 返回相对于当前工作目录的路径（用于输出信息），无法转换时原样返回
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
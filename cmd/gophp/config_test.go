@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigRejectsTopLevelServiceWithServices(t *testing.T) {
+	writeConfig(t, "service: legacy\nsource: legacy.go\n"+multiServiceConfig)
+	_, err := loadConfig()
+	if err == nil || !strings.Contains(err.Error(), "services") {
+		t.Fatalf("err = %v, want an error about top-level service with services", err)
+	}
+}
+
+func TestLoadConfigTopLevelService(t *testing.T) {
+	writeConfig(t, "service: legacy\nsource: legacy.go\n")
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Services) != 1 || config.Services[0].Name != "legacy" || config.Services[0].Source != "legacy.go" {
+		t.Fatalf("Services = %+v, want the single legacy service", config.Services)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLinesReconstructsInputs(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+	}{
+		{"both empty", "", ""},
+		{"from empty", "", "a\nb\n"},
+		{"to empty", "a\nb\n", ""},
+		{"identical", "a\nb\nc\n", "a\nb\nc\n"},
+		{"insert middle", "a\nc\n", "a\nb\nc\n"},
+		{"delete middle", "a\nb\nc\n", "a\nc\n"},
+		{"replace all", "a\nb\n", "c\nd\n"},
+		{"no trailing newline", "a\nb", "a\nb\n"},
+		{"append after missing newline", "a", "a\nb"},
+		{"repeated lines", "a\na\nb\na\n", "a\nb\na\na\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := diffLines(splitLines(tt.before), splitLines(tt.after))
+			var before, after strings.Builder
+			for _, op := range ops {
+				if op.kind != '+' {
+					before.WriteString(op.line)
+				}
+				if op.kind != '-' {
+					after.WriteString(op.line)
+				}
+			}
+			if before.String() != tt.before || after.String() != tt.after {
+				t.Fatalf("ops %q reconstruct %q -> %q", ops, before.String(), after.String())
+			}
+		})
+	}
+}
+
+func TestDiffLinesIsMinimal(t *testing.T) {
+	ops := diffLines(splitLines("a\nb\nc\nd\n"), splitLines("a\nx\nc\nd\n"))
+	changes := 0
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changes++
+		}
+	}
+	if changes != 2 {
+		t.Fatalf("got %d changed lines, want 2: %q", changes, ops)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          string
+	}{
+		{
+			name:   "identical",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "from empty",
+			before: "",
+			after:  "a\n",
+			want:   "--- a/x.go\n+++ b/x.go\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:   "to empty",
+			before: "a\nb\n",
+			after:  "",
+			want:   "--- a/x.go\n+++ b/x.go\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:   "context is trimmed",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			after:  "1\n2\n3\n4\nX\n6\n7\n8\n",
+			want:   "--- a/x.go\n+++ b/x.go\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+X\n 6\n 7\n 8\n",
+		},
+		{
+			name:   "missing trailing newline",
+			before: "a\nb",
+			after:  "a\nb\n",
+			want:   "--- a/x.go\n+++ b/x.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:   "nearby changes share a hunk",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			after:  "X\n2\n3\n4\n5\n6\n7\nY\n",
+			want:   "--- a/x.go\n+++ b/x.go\n@@ -1,8 +1,8 @@\n-1\n+X\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+Y\n",
+		},
+		{
+			name:   "distant changes get separate hunks",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			after:  "X\n2\n3\n4\n5\n6\n7\n8\n9\n10\nY\n",
+			want:   "--- a/x.go\n+++ b/x.go\n@@ -1,4 +1,4 @@\n-1\n+X\n 2\n 3\n 4\n@@ -8,4 +8,4 @@\n 8\n 9\n 10\n-11\n+Y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("x.go", []byte(tt.before), []byte(tt.after)); got != tt.want {
+				t.Fatalf("unifiedDiff:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wuwuseo/gophpffi/generator"
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix [source.go|dir|import-path]",
+	Short: "自动修复常见的导出问题",
+	Long: `基于语法树改写 Go 源码，修复 lint 报告的常见导出问题：
+
+  - 含 //export 的文件缺少 import "C"
+  - 包中缺少 func main() {}
+  - //export 名称与函数名不一致（以函数名为准）
+  - //export 或 //gophp: 指令与声明之间的空行
+  - 签名可以直接导出的 //gophp:export 函数改为 //export（使用 DTO 的函数保持不变）
+
+--diff 只输出统一差异格式的修改预览，不写入文件。无法自动修复的问题会在最后列出。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runFix,
+}
+
+// fixDiff 为 true 时只输出差异，不写入文件
+var fixDiff bool
+
+func init() {
+	fixCmd.Flags().BoolVar(&fixDiff, "diff", false, "只显示修改的差异，不写入文件")
+	fixCmd.Flags().StringVar(&buildTags, "tags", "", "逗号分隔的构建标签")
+	fixCmd.Flags().StringVar(&buildProfile, "profile", "", "构建配置档，解析源码时使用其中的构建标签")
+	fixCmd.Flags().StringSliceVar(&serviceFilter, "service", nil, "只处理 .gophp.yaml 中指定的服务（可重复或以逗号分隔）")
+	rootCmd.AddCommand(fixCmd)
+}
+
+func runFix(cmd *cobra.Command, args []string) error {
+	proj, err := resolveProject(args)
+	if err != nil {
+		return err
+	}
+	_, profile, err := resolveProfile(buildProfile, proj.Build)
+	if err != nil {
+		return err
+	}
+
+	fixed := 0
+	var remaining []generator.Diagnostic
+	for _, src := range proj.Services {
+		result, err := generator.Fix(generator.Options{Source: src.Source, Tags: profile.Tags})
+		if err != nil {
+			return fmt.Errorf("%s：修复失败：%w", src.Service, err)
+		}
+		for _, file := range result.Files {
+			if fixDiff {
+				fmt.Print(unifiedDiff(displayPath(file.Filename), file.Original, file.Fixed))
+				continue
+			}
+			if err := writeFixedFile(file); err != nil {
+				return err
+			}
+			fmt.Printf("✓ 已修复 %s\n", displayPath(file.Filename))
+			for _, d := range file.Applied {
+				fmt.Printf("  - %d 行：%s [%s]\n", d.Pos.Line, d.Message, d.Rule)
+			}
+		}
+		fixed += result.Fixed()
+		remaining = append(remaining, result.Remaining...)
+	}
+
+	if len(remaining) > 0 {
+		fmt.Fprintln(os.Stderr, "\n以下问题需要手动修复：")
+		printDiagnostics(os.Stderr, remaining)
+	}
+	switch {
+	case fixed == 0:
+		fmt.Println("✓ 没有可以自动修复的问题")
+	case !fixDiff:
+		fmt.Println("\n源码已修改，请运行 gophpffi generate 重新生成绑定")
+	}
+	return nil
+}
+
+// writeFixedFile 写回修复后的源文件，保留原有的文件权限
+func writeFixedFile(file generator.FixedFile) error {
+	info, err := os.Stat(file.Filename)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file.Filename, file.Fixed, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("写入 %s 失败：%w", displayPath(file.Filename), err)
+	}
+	return nil
+}
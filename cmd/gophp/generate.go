@@ -3,65 +3,171 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/wuwuseo/gophpffi/generator"
 )
 
 var generateCmd = &cobra.Command{
-	Use:   "generate [source.go]",
+	Use:   "generate [source.go|dir|import-path]",
 	Short: "生成 PHP FFI 绑定",
-	Long: `从 Go 源文件生成 PHP FFI 绑定。
+	Long: `从 Go 源文件或整个包生成 PHP FFI 绑定。
 	
-将会解析 Go 源文件并在 dist/ 目录中创建 PHP 服务类。`,
+参数可以是单个 .go 文件、包目录或导入路径。包模式下会收集
+包内所有文件（遵循构建约束与 --tags）中的导出函数，
+并在 output.dir（默认 dist/）目录中创建一个 PHP 服务类。
+
+生成前会执行与 gophpffi lint 相同的检查，发现错误时中止，可用 --no-lint 跳过。
+
+未指定参数时处理 .gophp.yaml 中的全部服务，可用 --service 选择其中一部分。`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runGenerate,
 }
 
+// resultMode 多返回值在 PHP 中的默认映射方式
+var resultMode string
+
+// jsonMode 是否对整个服务启用 JSON 桥接
+var jsonMode bool
+
+// runtimeMode 生成的 PHP 服务类使用的运行时
+var runtimeMode string
+
+// preloadMode 是否生成 opcache 预加载脚本
+var preloadMode bool
+
 func init() {
+	generateCmd.Flags().StringVar(&buildTags, "tags", "", "逗号分隔的构建标签")
+	generateCmd.Flags().StringVar(&buildProfile, "profile", "", "构建配置档，解析源码时使用其中的构建标签")
+	generateCmd.Flags().StringVar(&resultMode, "results", "array", "多返回值的映射方式：array（位置数组）或 class（结果类）")
+	generateCmd.Flags().BoolVar(&jsonMode, "json", false, "通过 JSON 桥接含 map/结构体的导出函数")
+	generateCmd.Flags().StringVar(&runtimeMode, "runtime", "", "PHP 运行时：library（继承 GoLibraryBase，默认）或 embedded（自包含，仅依赖 ext-ffi）")
+	generateCmd.Flags().BoolVar(&preloadMode, "preload", false, "生成 opcache 预加载脚本并通过 FFI::scope 获取 FFI 实例（需要 --runtime=embedded）")
+	generateCmd.Flags().BoolVar(&noLint, "no-lint", false, "跳过生成前的 FFI 导出检查")
+	generateCmd.Flags().StringSliceVar(&serviceFilter, "service", nil, "只处理 .gophp.yaml 中指定的服务（可重复或以逗号分隔）")
 	rootCmd.AddCommand(generateCmd)
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
-	// Load config or use args
-	config, err := loadConfig()
-	if err != nil && len(args) == 0 {
-		return fmt.Errorf("未指定源文件且找不到 .gophp.yaml")
+	proj, err := resolveProject(args)
+	if err != nil {
+		return err
 	}
-
-	var sourceFile string
-	if len(args) > 0 {
-		sourceFile = args[0]
-	} else {
-		sourceFile = config.Source
+	// 解析源码时使用与构建相同的标签
+	_, profile, err := resolveProfile(buildProfile, proj.Build)
+	if err != nil {
+		return err
 	}
 
 	fmt.Println("=== Go-PHP FFI 代码生成器 ===")
-	fmt.Printf("正在为以下文件生成 PHP 绑定：%s\n\n", sourceFile)
+	for _, src := range proj.Services {
+		if err := generateService(src, profile.Tags); err != nil {
+			return fmt.Errorf("%s：%w", src.Service, err)
+		}
+	}
+	fmt.Println("\n=== 代码生成完成！===")
+	return nil
+}
 
-	// Get absolute path
-	absPath, err := filepath.Abs(sourceFile)
+// generateService 为单个服务生成 PHP 绑定与 Go 适配层，tags 为解析源码时使用的构建标签
+func generateService(src *serviceSource, tags []string) error {
+	fmt.Printf("\n正在为服务 %s 生成 PHP 绑定：%s\n\n", src.Service, src.Source)
+
+	options := generatorOptions(src, tags)
+	if !noLint {
+		if err := lintService(options); err != nil {
+			return err
+		}
+	}
+	result, err := generator.Generate(options)
 	if err != nil {
-		return fmt.Errorf("获取绝对路径失败：%w", err)
+		return fmt.Errorf("生成失败：%w", err)
 	}
+	printGenerateResult(result)
+	return nil
+}
 
-	// Get current working directory
-	cwd, err := os.Getwd()
+// lintService 在生成前检查源码，只输出错误级别的诊断（警告由生成器自身报告）
+func lintService(options generator.Options) error {
+	result, err := generator.Lint(options)
 	if err != nil {
-		return fmt.Errorf("获取工作目录失败：%w", err)
+		return fmt.Errorf("检查失败：%w", err)
+	}
+	if !result.HasErrors() {
+		return nil
 	}
+	var errs []generator.Diagnostic
+	for _, d := range result.Diagnostics {
+		if d.Severity == generator.SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	printDiagnostics(os.Stderr, errs)
+	return fmt.Errorf("源码中有 %d 个 FFI 导出错误，请修复后重试（--no-lint 可跳过检查）", len(errs))
+}
 
-	// Run the generator
-	generatorDir := filepath.Join(cwd, "generator")
-	genCmd := exec.Command("go", "run", ".", absPath)
-	genCmd.Dir = generatorDir
-	genCmd.Stdout = os.Stdout
-	genCmd.Stderr = os.Stderr
+// generatorOptions 返回服务对应的生成器选项（generate 与构建后的导出检查共用）
+func generatorOptions(src *serviceSource, tags []string) generator.Options {
+	runtime := runtimeMode
+	if runtime == "" {
+		runtime = src.Runtime
+	}
+	return generator.Options{
+		Source:      src.Source,
+		ServiceName: src.Service,
+		Namespace:   src.Namespace,
+		ClassName:   src.ClassName,
+		BaseClass:   src.BaseClass,
+		PHPFile:     src.PHPFile,
+		Tags:        tags,
+		ResultMode:  resultMode,
+		JSON:        jsonMode || src.JSON,
+		Runtime:     runtime,
+		Preload:     preloadMode || src.Preload,
+		OutputDir:   src.OutputDir,
+		LibDir:      src.LibDir,
+		Naming: generator.Naming{
+			Methods:    src.Naming.Methods,
+			Classes:    src.Naming.Classes,
+			Namespaces: src.Naming.Namespaces,
+			Acronyms:   src.Naming.Acronyms,
+		},
+	}
+}
 
-	if err := genCmd.Run(); err != nil {
-		return fmt.Errorf("生成失败：%w", err)
+// printGenerateResult 输出生成结果摘要，警告写入标准错误
+func printGenerateResult(result generator.Result) {
+	fmt.Println("已解析源文件：")
+	for _, file := range result.SourceFiles {
+		fmt.Printf("  - %s\n", filepath.Base(file))
 	}
+	fmt.Printf("服务名：%s\n", result.ServiceName)
+	fmt.Printf("ABI 哈希：%s\n", result.ABIHash)
 
-	return nil
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "警告：%s\n", warning)
+	}
+
+	fmt.Printf("找到 %d 个导出函数\n", len(result.Exports))
+	for _, exp := range result.Exports {
+		fmt.Printf("  - %s (%s:%d)\n", exp.Name, filepath.Base(exp.Pos.Filename), exp.Pos.Line)
+	}
+	if len(result.Structs) > 0 {
+		fmt.Printf("找到 %d 个导出结构体\n", len(result.Structs))
+		for _, def := range result.Structs {
+			fmt.Printf("  - %s (%s:%d)\n", def.Name, filepath.Base(def.Pos.Filename), def.Pos.Line)
+		}
+	}
+	if len(result.Handles) > 0 {
+		fmt.Printf("找到 %d 个句柄类型\n", len(result.Handles))
+		for _, h := range result.Handles {
+			fmt.Printf("  - %s (%s:%d)\n", h.Name, filepath.Base(h.Pos.Filename), h.Pos.Line)
+		}
+	}
+	fmt.Println()
+
+	for _, file := range result.Files {
+		fmt.Printf("✓ 已生成 %s\n", displayPath(file))
+	}
 }
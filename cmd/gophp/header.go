@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// blockComment 匹配 C 块注释
+	blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	// definedExpr 匹配 #if 中的 defined(X) / defined X
+	definedExpr = regexp.MustCompile(`^(!)?\s*defined\s*\(?\s*(\w+)\s*\)?$`)
+	// droppedDecl 匹配 PHP FFI 无法解析或不需要的声明
+	droppedDecl = regexp.MustCompile(`_check_for_|static_assert|_Complex|_GoStringLen|_GoStringPtr`)
+)
+
+// ffiHeaderPath 返回 cgo 头文件对应的 FFI 头文件路径（<name>.ffi.h）
+func ffiHeaderPath(header string) string {
+	return strings.TrimSuffix(header, ".h") + ".ffi.h"
+}
+
+// writeFFIHeader 将 cgo 生成的头文件清理为可直接用于 FFI::load 的 .ffi.h 文件
+// FFI_LIB 写入库文件的绝对路径：dlopen 只在加载器路径中查找文件名，不会查找头文件所在目录
+func writeFFIHeader(header, library, scope string) (string, error) {
+	src, err := os.ReadFile(header)
+	if err != nil {
+		return "", err
+	}
+	libPath, err := filepath.Abs(library)
+	if err != nil {
+		return "", err
+	}
+	body, err := sanitizeHeader(src)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", header, err)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(fmt.Sprintf("/* Code generated by gophpffi from %s. DO NOT EDIT. */\n", filepath.Base(header)))
+	out.WriteString(fmt.Sprintf("#define FFI_SCOPE %q\n", scope))
+	out.WriteString(fmt.Sprintf("#define FFI_LIB %q\n\n", filepath.ToSlash(libPath)))
+	out.Write(body)
+
+	target := ffiHeaderPath(header)
+	return target, os.WriteFile(target, out.Bytes(), 0644)
+}
+
+// sanitizeHeader 去除 cgo 头文件中的注释与预处理指令，只保留 PHP 需要的类型定义与函数原型
+// 条件编译按"未定义任何编译器宏"求值：__cplusplus、_MSC_VER 等分支会被丢弃，
+// 头文件自身 #define 的保护宏视为已定义
+func sanitizeHeader(src []byte) ([]byte, error) {
+	src = blockComment.ReplaceAll(src, nil)
+
+	defined := make(map[string]bool)
+	// active 记录每层条件是否生效，taken 记录该层是否已有分支生效
+	var active, taken []bool
+	enabled := func() bool {
+		for _, a := range active {
+			if !a {
+				return false
+			}
+		}
+		return true
+	}
+
+	var out bytes.Buffer
+	blank := true
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimRight(line[:i], " \t")
+		}
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "#") {
+			directive, arg, _ := strings.Cut(strings.TrimSpace(trimmed[1:]), " ")
+			arg = strings.TrimSpace(arg)
+			switch directive {
+			case "ifdef", "ifndef", "if":
+				cond := false
+				switch directive {
+				case "ifdef":
+					cond = defined[arg]
+				case "ifndef":
+					cond = !defined[arg]
+				default:
+					cond = evalCondition(arg, defined)
+				}
+				active = append(active, cond)
+				taken = append(taken, cond)
+			case "elif", "else":
+				if len(active) == 0 {
+					return nil, fmt.Errorf("unexpected #%s", directive)
+				}
+				last := len(active) - 1
+				cond := !taken[last] && (directive == "else" || evalCondition(arg, defined))
+				active[last] = cond
+				taken[last] = taken[last] || cond
+			case "endif":
+				if len(active) == 0 {
+					return nil, fmt.Errorf("unexpected #endif")
+				}
+				active, taken = active[:len(active)-1], taken[:len(taken)-1]
+			case "define":
+				if enabled() {
+					name, _, _ := strings.Cut(arg, " ")
+					defined[name] = true
+				}
+			}
+			// #line、#include、#pragma 等指令一律丢弃
+			continue
+		}
+		if !enabled() || droppedDecl.MatchString(line) {
+			continue
+		}
+
+		// 合并 cgo 的 _GoString_ 间接定义
+		line = strings.ReplaceAll(line, "_GoString_", "GoString")
+		if strings.TrimSpace(line) == "typedef GoString GoString;" {
+			continue
+		}
+		line = strings.TrimPrefix(line, "extern ")
+
+		if trimmed == "" {
+			if !blank {
+				out.WriteString("\n")
+			}
+			blank = true
+			continue
+		}
+		out.WriteString(line + "\n")
+		blank = false
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(active) != 0 {
+		return nil, fmt.Errorf("unterminated #if")
+	}
+	return out.Bytes(), nil
+}
+
+// evalCondition 对 #if/#elif 条件做保守求值，仅支持 [!]defined(X) 以及以 || / && 连接的组合，
+// 无法识别的表达式视为假
+func evalCondition(expr string, defined map[string]bool) bool {
+	if strings.Contains(expr, "||") {
+		for _, part := range strings.Split(expr, "||") {
+			if evalCondition(strings.TrimSpace(part), defined) {
+				return true
+			}
+		}
+		return false
+	}
+	if strings.Contains(expr, "&&") {
+		for _, part := range strings.Split(expr, "&&") {
+			if !evalCondition(strings.TrimSpace(part), defined) {
+				return false
+			}
+		}
+		return true
+	}
+	if m := definedExpr.FindStringSubmatch(expr); m != nil {
+		return defined[m[2]] != (m[1] == "!")
+	}
+	return expr == "1"
+}
@@ -0,0 +1,124 @@
+package main
+
+import "testing"
+
+func TestEvalCondition(t *testing.T) {
+	defined := map[string]bool{"GUARD": true, "OTHER": true}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"defined(GUARD)", true},
+		{"defined GUARD", true},
+		{"defined( GUARD )", true},
+		{"!defined(GUARD)", false},
+		{"! defined(MISSING)", true},
+		{"defined(MISSING)", false},
+		{"defined(MISSING) || defined(GUARD)", true},
+		{"defined(GUARD) && !defined(OTHER)", false},
+		{"defined(GUARD) && defined(OTHER)", true},
+		{"!defined(__cplusplus) || _MSVC_LANG <= 201402L", true},
+		{"_MSVC_LANG <= 201402L", false},
+		{"1", true},
+		{"0", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := evalCondition(tt.expr, defined); got != tt.want {
+			t.Errorf("evalCondition(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			src:  "",
+			want: "",
+		},
+		{
+			name: "comments and directives removed",
+			src:  "/* Code generated by cmd/cgo; DO NOT EDIT. */\n#line 1 \"x.go\"\n#include <stddef.h>\n\nextern int Add(int a, int b); // add\n",
+			want: "int Add(int a, int b);\n",
+		},
+		{
+			name: "no trailing newline",
+			src:  "extern int Add(int a, int b);",
+			want: "int Add(int a, int b);\n",
+		},
+		{
+			name: "include guard defined by the header",
+			src:  "#ifndef GUARD\n#define GUARD\ntypedef int GoInt;\n#endif\n#ifndef GUARD\ntypedef long Twice;\n#endif\n",
+			want: "typedef int GoInt;\n",
+		},
+		{
+			name: "compiler branches use the else side",
+			src:  "#ifdef _MSC_VER\ntypedef _Fcomplex GoComplex64;\n#else\ntypedef float _Complex GoComplex64;\ntypedef int Kept;\n#endif\n",
+			want: "typedef int Kept;\n",
+		},
+		{
+			name: "nested if and elif",
+			src: "#ifdef __cplusplus\n#if A\nint cpp1;\n#elif B\nint cpp2;\n#endif\n#else\n" +
+				"#if defined(X)\nint x;\n#elif !defined(Y)\nint noY;\n#elif 1\nint later;\n#else\nint fallback;\n#endif\n#endif\n",
+			want: "int noY;\n",
+		},
+		{
+			name: "elif taken after a false if",
+			src:  "#if defined(MISSING)\nint a;\n#elif 1\nint b;\n#else\nint c;\n#endif\n",
+			want: "int b;\n",
+		},
+		{
+			name: "define inside a disabled branch is ignored",
+			src:  "#ifdef __cplusplus\n#define CPP\n#endif\n#ifdef CPP\nint cpp;\n#endif\nint c;\n",
+			want: "int c;\n",
+		},
+		{
+			name: "GoString indirection",
+			src:  "typedef struct { const char *p; ptrdiff_t n; } _GoString_;\ntypedef _GoString_ GoString;\nextern void Echo(GoString s);\n",
+			want: "typedef struct { const char *p; ptrdiff_t n; } GoString;\nvoid Echo(GoString s);\n",
+		},
+		{
+			name: "static assertions and blank runs",
+			src:  "typedef char _check_for_64_bit_pointer_matching_GoInt[sizeof(void*)==64/8 ? 1:-1];\n\n\n\nint a;\n\n\nint b;\n",
+			want: "int a;\n\nint b;\n",
+		},
+		{
+			name:    "unterminated if",
+			src:     "#ifdef X\nint a;\n",
+			wantErr: true,
+		},
+		{
+			name:    "stray endif",
+			src:     "int a;\n#endif\n",
+			wantErr: true,
+		},
+		{
+			name:    "stray else",
+			src:     "#else\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sanitizeHeader([]byte(tt.src))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("sanitizeHeader:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var initCmd = &cobra.Command{
@@ -15,7 +19,7 @@ var initCmd = &cobra.Command{
 	
 将会创建：
   - [ServiceName].go 包含模板函数
-  - 更新构建配置`,
+  - 在 .gophp.yaml 的 services 列表中追加该服务（文件不存在时创建）`,
 	Args: cobra.ExactArgs(1),
 	RunE: runInit,
 }
@@ -32,6 +36,15 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Printf("   初始化新服务：%s\n", serviceName)
 	fmt.Printf("========================================\n\n")
 
+	// 服务已在配置文件中时不再重复创建
+	if config, err := loadConfig(); err == nil {
+		for _, svc := range config.Services {
+			if svc.Name == serviceName {
+				return fmt.Errorf(".gophp.yaml 中已存在服务 %s", serviceName)
+			}
+		}
+	}
+
 	// Check if file exists
 	if _, err := os.Stat(goFile); err == nil {
 		fmt.Printf("警告：%s 已存在！\n", goFile)
@@ -80,19 +93,17 @@ func main() {}
 	fmt.Printf("✓ 已创建 %s\n\n", goFile)
 
 	// Update .gophp.yaml config file
-	fmt.Println("[2/3] 正在创建 .gophp.yaml 配置文件...")
-	configContent := fmt.Sprintf(`# Go-PHP FFI Service Configuration
-service: %s
-source: %s
-output:
-  dir: dist
-  lib_dir: dist/lib
-`, serviceName, goFile)
-
-	if err := os.WriteFile(".gophp.yaml", []byte(configContent), 0644); err != nil {
-		return fmt.Errorf("创建配置文件失败：%w", err)
+	fmt.Println("[2/3] 正在更新 .gophp.yaml 配置文件...")
+	created, err := addServiceToConfig(serviceName, goFile)
+	if err != nil {
+		return fmt.Errorf("更新配置文件失败：%w", err)
+	}
+	if created {
+		fmt.Println("✓ 已创建 .gophp.yaml")
+	} else {
+		fmt.Printf("✓ 已将服务 %s 添加到 .gophp.yaml\n", serviceName)
 	}
-	fmt.Println("✓ 已创建 .gophp.yaml\n")
+	fmt.Println()
 
 	fmt.Println("[3/3] 设置完成")
 	fmt.Println()
@@ -107,6 +118,7 @@ output:
 	fmt.Println("下一步：")
 	fmt.Printf("  1. 编辑 %s 并添加你的导出函数\n", goFile)
 	fmt.Println("  2. 运行：gophpffi make   (生成 PHP 绑定并构建)")
+	fmt.Printf("     或：gophpffi make --service %s   (只处理该服务)\n", serviceName)
 	fmt.Println()
 	fmt.Println("导出函数示例格式：")
 	fmt.Println("  //export YourFunction")
@@ -116,3 +128,107 @@ output:
 
 	return nil
 }
+
+// configTemplate 是新建 .gophp.yaml 时使用的模板
+const configTemplate = `# Go-PHP FFI Service Configuration
+output:
+  dir: dist
+  lib_dir: dist/lib
+services:
+  - name: %s
+    source: %s
+`
+
+// addServiceToConfig 将服务追加到 .gophp.yaml 的 services 列表，文件不存在时按模板创建
+// 旧式的顶层 service/source 会先迁移为 services 中的第一项；其余配置与注释保持不变
+func addServiceToConfig(name, source string) (created bool, err error) {
+	data, err := os.ReadFile(configFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, os.WriteFile(configFile, []byte(fmt.Sprintf(configTemplate, name, source)), 0644)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return false, fmt.Errorf("%s 的顶层必须是映射", configFile)
+	}
+
+	services := mappingValue(root, "services")
+	if services == nil {
+		services = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		// 迁移旧式的单服务配置
+		if legacy := mappingValue(root, "source"); legacy != nil {
+			entry := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for _, key := range []string{"service", "source", "namespace"} {
+				if value := mappingValue(root, key); value != nil {
+					entryKey := key
+					if key == "service" {
+						entryKey = "name"
+					}
+					entry.Content = append(entry.Content, scalarNode(entryKey), value)
+					removeMappingKey(root, key)
+				}
+			}
+			services.Content = append(services.Content, entry)
+		}
+		root.Content = append(root.Content, scalarNode("services"), services)
+	}
+
+	services.Content = append(services.Content, &yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+		Content: []*yaml.Node{
+			scalarNode("name"), scalarNode(name),
+			scalarNode("source"), scalarNode(source),
+		},
+	})
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return false, err
+	}
+	if err := encoder.Close(); err != nil {
+		return false, err
+	}
+	return false, os.WriteFile(configFile, out.Bytes(), 0644)
+}
+
+// mappingValue 返回映射节点中 key 对应的值节点，不存在时返回 nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeMappingKey 从映射节点中删除 key，键上方的注释转移到下一个键
+func removeMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			if comment := mapping.Content[i].HeadComment; comment != "" && i+2 < len(mapping.Content) {
+				next := mapping.Content[i+2]
+				next.HeadComment = strings.TrimSpace(comment + "\n" + next.HeadComment)
+			}
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// scalarNode 创建字符串标量节点
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wuwuseo/gophpffi/generator"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [source.go|dir|import-path]",
+	Short: "检查导出函数在 PHP FFI 下的问题",
+	Long: `检查 Go 源码中能够编译、但无法或不安全地从 PHP 调用的导出：
+签名中的 map、interface、channel、函数值与 Go 结构体，返回 Go 指针，
+含 Go 指针的 //gophp:struct 字段，缺少 import "C" 或 func main，非 main 包，
+以及 //export 与 func 之间的空行等。
+
+每个问题包含位置（file:line:col）、规则 ID 与修复建议。--format json 输出
+JSON 数组，--format vet 输出与 go vet -json 相同的结构，便于编辑器与 CI 解析。
+存在错误级别的问题时命令返回非零状态。generate 在生成前也会执行同样的检查。`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLint,
+}
+
+// lintFormat 是 --format 指定的输出格式
+var lintFormat string
+
+// noLint 为 true 时 generate 跳过生成前的检查
+var noLint bool
+
+func init() {
+	lintCmd.Flags().StringVar(&buildTags, "tags", "", "逗号分隔的构建标签")
+	lintCmd.Flags().StringVar(&buildProfile, "profile", "", "构建配置档，检查时使用其中的构建标签")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "输出格式：text、json 或 vet（与 go vet -json 兼容）")
+	lintCmd.Flags().StringSliceVar(&serviceFilter, "service", nil, "只处理 .gophp.yaml 中指定的服务（可重复或以逗号分隔）")
+	rootCmd.AddCommand(lintCmd)
+}
+
+// lintDiagnostic 是 --format json 输出的单个诊断
+type lintDiagnostic struct {
+	Service  string `json:"service"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Fix      string `json:"fix,omitempty"`
+}
+
+// vetDiagnostic 与 go vet -json 输出中的诊断结构一致
+type vetDiagnostic struct {
+	Posn           string         `json:"posn"`
+	Message        string         `json:"message"`
+	SuggestedFixes []vetSuggested `json:"suggested_fixes,omitempty"`
+}
+
+// vetSuggested 是 go vet -json 中的修复建议（不含具体编辑）
+type vetSuggested struct {
+	Message string `json:"message"`
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if lintFormat != "text" && lintFormat != "json" && lintFormat != "vet" {
+		return fmt.Errorf("未知的输出格式 %s（应为 text、json 或 vet）", lintFormat)
+	}
+	proj, err := resolveProject(args)
+	if err != nil {
+		return err
+	}
+	_, profile, err := resolveProfile(buildProfile, proj.Build)
+	if err != nil {
+		return err
+	}
+
+	var (
+		diagnostics []lintDiagnostic
+		vet         = make(map[string]map[string][]vetDiagnostic)
+		errorCount  int
+	)
+	for _, src := range proj.Services {
+		result, err := generator.Lint(generator.Options{Source: src.Source, Tags: profile.Tags})
+		if err != nil {
+			return fmt.Errorf("%s：检查失败：%w", src.Service, err)
+		}
+		if lintFormat == "text" {
+			printDiagnostics(os.Stdout, result.Diagnostics)
+		}
+		for _, d := range result.Diagnostics {
+			if d.Severity == generator.SeverityError {
+				errorCount++
+			}
+			diagnostics = append(diagnostics, lintDiagnostic{
+				Service:  src.Service,
+				File:     d.Pos.Filename,
+				Line:     d.Pos.Line,
+				Column:   d.Pos.Column,
+				Rule:     d.Rule,
+				Severity: d.Severity,
+				Message:  d.Message,
+				Fix:      d.Fix,
+			})
+			if vet[result.Package] == nil {
+				vet[result.Package] = make(map[string][]vetDiagnostic)
+			}
+			vd := vetDiagnostic{Posn: d.Pos.String(), Message: d.Message}
+			if d.Fix != "" {
+				vd.SuggestedFixes = []vetSuggested{{Message: d.Fix}}
+			}
+			vet[result.Package][d.Rule] = append(vet[result.Package][d.Rule], vd)
+		}
+	}
+
+	switch lintFormat {
+	case "json":
+		if diagnostics == nil {
+			diagnostics = []lintDiagnostic{}
+		}
+		if err := writeJSON(os.Stdout, diagnostics); err != nil {
+			return err
+		}
+	case "vet":
+		if err := writeJSON(os.Stdout, vet); err != nil {
+			return err
+		}
+	default:
+		if len(diagnostics) == 0 {
+			fmt.Println("✓ 未发现问题")
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("发现 %d 个错误", errorCount)
+	}
+	return nil
+}
+
+// printDiagnostics 以 file:line:col 形式输出诊断及修复建议
+func printDiagnostics(w io.Writer, diagnostics []generator.Diagnostic) {
+	for _, d := range diagnostics {
+		severity := "错误"
+		if d.Severity == generator.SeverityWarning {
+			severity = "警告"
+		}
+		pos := d.Pos
+		pos.Filename = displayPath(pos.Filename)
+		fmt.Fprintf(w, "%s: %s：%s [%s]\n", pos, severity, d.Message, d.Rule)
+		if d.Fix != "" {
+			fmt.Fprintf(w, "\t修复建议：%s\n", d.Fix)
+		}
+	}
+}
+
+// writeJSON 以缩进格式输出 JSON
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(v)
+}
@@ -2,20 +2,36 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var makeCmd = &cobra.Command{
-	Use:   "make",
+	Use:   "make [source.go|dir|import-path]",
 	Short: "生成绑定并构建库（完整构建）",
 	Long: `完整的构建流程：生成 PHP 绑定并构建共享库。
 	
 相当于依次运行 'gophpffi generate' 和 'gophpffi build'。`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runMake,
 }
 
 func init() {
+	makeCmd.Flags().StringVar(&buildTags, "tags", "", "逗号分隔的构建标签")
+	makeCmd.Flags().StringVar(&buildProfile, "profile", "", "构建配置档（dev、release 或 .gophp.yaml 中 build.profiles 定义的名称）")
+	makeCmd.Flags().StringVar(&resultMode, "results", "array", "多返回值的映射方式：array（位置数组）或 class（结果类）")
+	makeCmd.Flags().BoolVar(&jsonMode, "json", false, "通过 JSON 桥接含 map/结构体的导出函数")
+	makeCmd.Flags().StringVar(&runtimeMode, "runtime", "", "PHP 运行时：library（继承 GoLibraryBase，默认）或 embedded（自包含，仅依赖 ext-ffi）")
+	makeCmd.Flags().BoolVar(&preloadMode, "preload", false, "生成 opcache 预加载脚本并通过 FFI::scope 获取 FFI 实例（需要 --runtime=embedded）")
+	makeCmd.Flags().StringSliceVar(&buildTargetFlags, "target", nil, "目标平台（os/arch，可重复或以逗号分隔），例如 linux/arm64")
+	makeCmd.Flags().IntVarP(&buildJobs, "jobs", "j", 0, "并行构建的目标数（默认使用配置中的 jobs 或 CPU 核数）")
+	makeCmd.Flags().BoolVar(&forceBuild, "force", false, "忽略构建缓存，重新构建所有目标")
+	makeCmd.Flags().BoolVar(&noLint, "no-lint", false, "跳过生成前的 FFI 导出检查")
+	makeCmd.Flags().BoolVar(&noVerify, "no-verify", false, "跳过构建后的导出符号检查")
+	makeCmd.Flags().StringSliceVar(&serviceFilter, "service", nil, "只处理 .gophp.yaml 中指定的服务（可重复或以逗号分隔）")
 	rootCmd.AddCommand(makeCmd)
 }
 
@@ -43,7 +59,19 @@ func runMake(cmd *cobra.Command, args []string) error {
 	fmt.Println("   构建完成！")
 	fmt.Println("========================================")
 	fmt.Println()
-	fmt.Println("请检查 dist/ 目录中的生成文件。")
+	if proj, err := resolveProject(args); err == nil {
+		var dirs []string
+		for _, src := range proj.Services {
+			dir := displayPath(src.OutputDir)
+			if src.PHPFile != "" {
+				dir = displayPath(filepath.Dir(src.PHPFile))
+			}
+			if !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+		fmt.Printf("请检查 %s 目录中的生成文件。\n", strings.Join(dirs, "、"))
+	}
 	fmt.Println("使用方法：在你的 PHP 代码中导入 PHP 服务文件。")
 
 	return nil
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// buildProfile 是 --profile 指定的构建配置档
+var buildProfile string
+
+// BuildConfig 是 .gophp.yaml 中的 build 配置
+type BuildConfig struct {
+	Profile  string                  `yaml:"profile"`  // 未指定 --profile 时使用的配置档，默认为 dev
+	Version  string                  `yaml:"version"`  // {{.Version}} 的值，为空时取 git describe 的结果
+	Profiles map[string]BuildProfile `yaml:"profiles"` // 命名的配置档，同名时覆盖内置的 dev/release
+}
+
+// BuildProfile 描述传给 go build 的参数与环境变量
+// 字符串中可以使用 {{.Version}}、{{.GitCommit}}、{{.Service}}、{{.OS}}、{{.Arch}}、{{.Profile}}
+type BuildProfile struct {
+	Flags   []string          `yaml:"flags"`   // 额外的 go build 参数，例如 -trimpath、-race
+	LDFlags string            `yaml:"ldflags"` // 传给 -ldflags 的值，例如 -s -w -X main.version={{.Version}}
+	Tags    []string          `yaml:"tags"`    // 构建标签
+	Env     map[string]string `yaml:"env"`     // 环境变量，例如 CGO_CFLAGS、CGO_LDFLAGS、GOFLAGS
+}
+
+// builtinProfiles 是未在配置文件中定义时可用的配置档
+var builtinProfiles = map[string]BuildProfile{
+	"dev":     {},
+	"release": {Flags: []string{"-trimpath"}, LDFlags: "-s -w"},
+}
+
+// resolveProfile 返回本次构建使用的配置档：--profile 优先，其次为配置中的 build.profile，默认为 dev
+// --tags 指定的标签会追加到配置档的标签中
+func resolveProfile(name string, config BuildConfig) (string, BuildProfile, error) {
+	if name == "" {
+		name = config.Profile
+	}
+	if name == "" {
+		name = "dev"
+	}
+	profile, ok := config.Profiles[name]
+	if !ok {
+		if profile, ok = builtinProfiles[name]; !ok {
+			return "", BuildProfile{}, fmt.Errorf("未定义的构建配置档 %s", name)
+		}
+	}
+	profile = profile.merge(BuildProfile{Tags: splitTags(buildTags)})
+	return name, profile, nil
+}
+
+// merge 返回叠加 override 后的配置档：参数与标签追加，ldflags 以空格拼接，同名环境变量以 override 为准
+func (p BuildProfile) merge(override BuildProfile) BuildProfile {
+	merged := BuildProfile{
+		Flags:   append(slices.Clone(p.Flags), override.Flags...),
+		LDFlags: strings.TrimSpace(p.LDFlags + " " + override.LDFlags),
+		Tags:    slices.Clone(p.Tags),
+		Env:     make(map[string]string, len(p.Env)+len(override.Env)),
+	}
+	for _, tag := range override.Tags {
+		if !slices.Contains(merged.Tags, tag) {
+			merged.Tags = append(merged.Tags, tag)
+		}
+	}
+	for k, v := range p.Env {
+		merged.Env[k] = v
+	}
+	for k, v := range override.Env {
+		merged.Env[k] = v
+	}
+	return merged
+}
+
+// tagList 返回传给 -tags 的逗号分隔列表
+func (p BuildProfile) tagList() string {
+	return strings.Join(p.Tags, ",")
+}
+
+// goBuildArgs 返回 go build 中 -buildmode 之后、包参数之前的参数
+func (p BuildProfile) goBuildArgs() []string {
+	args := slices.Clone(p.Flags)
+	if len(p.Tags) > 0 {
+		args = append(args, "-tags", p.tagList())
+	}
+	if p.LDFlags != "" {
+		args = append(args, "-ldflags", p.LDFlags)
+	}
+	return args
+}
+
+// envList 返回按变量名排序的 KEY=VALUE 列表
+func (p BuildProfile) envList() []string {
+	keys := make([]string, 0, len(p.Env))
+	for k := range p.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, k+"="+p.Env[k])
+	}
+	return env
+}
+
+// expand 展开参数、ldflags 与环境变量中的模板变量
+func (p BuildProfile) expand(vars buildVars) (BuildProfile, error) {
+	expand := func(s string) (string, error) {
+		if !strings.Contains(s, "{{") {
+			return s, nil
+		}
+		t, err := template.New("build").Option("missingkey=error").Parse(s)
+		if err != nil {
+			return "", fmt.Errorf("无效的模板 %q：%w", s, err)
+		}
+		var sb strings.Builder
+		if err := t.Execute(&sb, vars); err != nil {
+			return "", fmt.Errorf("展开模板 %q 失败：%w", s, err)
+		}
+		return sb.String(), nil
+	}
+
+	expanded := BuildProfile{Tags: p.Tags, Env: make(map[string]string, len(p.Env))}
+	var err error
+	for _, flag := range p.Flags {
+		if flag, err = expand(flag); err != nil {
+			return BuildProfile{}, err
+		}
+		expanded.Flags = append(expanded.Flags, flag)
+	}
+	if expanded.LDFlags, err = expand(p.LDFlags); err != nil {
+		return BuildProfile{}, err
+	}
+	for k, v := range p.Env {
+		if expanded.Env[k], err = expand(v); err != nil {
+			return BuildProfile{}, err
+		}
+	}
+	return expanded, nil
+}
+
+// buildVars 是构建参数模板中可用的变量
+type buildVars struct {
+	Service string
+	OS      string
+	Arch    string
+	Profile string
+	git     *gitInfo
+}
+
+// Version 返回 build.version，未配置时为 git describe --tags --always --dirty 的结果
+func (v buildVars) Version() string {
+	return v.git.get().version
+}
+
+// GitCommit 返回当前提交的短哈希
+func (v buildVars) GitCommit() string {
+	return v.git.get().commit
+}
+
+// gitInfo 在首次使用时读取 git 信息，同一次构建的所有目标共用
+type gitInfo struct {
+	once    sync.Once
+	version string
+	commit  string
+}
+
+// newGitInfo 创建 gitInfo，version 非空时作为 {{.Version}} 的值
+func newGitInfo(version string) *gitInfo {
+	return &gitInfo{version: version}
+}
+
+func (g *gitInfo) get() *gitInfo {
+	g.once.Do(func() {
+		git := func(args ...string) string {
+			out, err := exec.Command("git", args...).Output()
+			if err != nil {
+				return ""
+			}
+			return strings.TrimSpace(string(out))
+		}
+		if g.version == "" {
+			g.version = git("describe", "--tags", "--always", "--dirty")
+		}
+		if g.version == "" {
+			g.version = "dev"
+		}
+		if g.commit = git("rev-parse", "--short", "HEAD"); g.commit == "" {
+			g.commit = "unknown"
+		}
+	})
+	return g
+}
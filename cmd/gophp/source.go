@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/wuwuseo/gophpffi/generator"
+)
+
+// buildTags 是 generate/build/make 共用的 --tags 参数
+var buildTags string
+
+// splitTags 将逗号或空格分隔的构建标签拆分为列表
+func splitTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// serviceFilter 是 --service 指定的服务名（generate/build/make 共用）
+var serviceFilter []string
+
+// serviceSource 描述一次生成或构建所针对的源码
+type serviceSource struct {
+	Source    string        // 源文件、包目录或导入路径
+	Service   string        // 服务名
+	Namespace string        // PHP 命名空间模板，为空时使用生成器默认值
+	ClassName string        // PHP 服务类名模板
+	BaseClass string        // 服务类的父类
+	PHPFile   string        // PHP 服务类文件路径（绝对路径），为空时位于 OutputDir
+	JSON      bool          // 是否启用服务级 JSON 桥接
+	Runtime   string        // PHP 运行时模式（配置文件中的 runtime）
+	Preload   bool          // 是否生成 opcache 预加载脚本
+	Targets   []BuildTarget // 配置文件中的交叉编译目标
+	Naming    NamingConfig  // PHP 名称的命名策略
+	// OutputDir 与 LibDir 是 PHP 文件与共享库的输出目录（绝对路径）
+	OutputDir string
+	LibDir    string
+}
+
+// project 描述本次命令要处理的服务
+type project struct {
+	Services []*serviceSource
+	Jobs     int         // 配置文件中的并行构建数
+	Build    BuildConfig // 配置文件中的构建配置档
+}
+
+// resolveProject 根据命令行参数或 .gophp.yaml 确定要处理的服务
+// 参数可以是单个 .go 文件、包含 package main 的目录或导入路径；
+// 未指定参数时使用配置文件中的全部服务，或 --service 选中的子集
+func resolveProject(args []string) (*project, error) {
+	if len(args) > 0 {
+		if len(serviceFilter) > 0 {
+			return nil, fmt.Errorf("--service 只能用于 .gophp.yaml 中配置的服务，不能与源码参数同时使用")
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("获取工作目录失败：%w", err)
+		}
+		return &project{Services: []*serviceSource{{
+			Source:    args[0],
+			Service:   serviceNameFromSource(args[0]),
+			OutputDir: resolvePath(cwd, defaultOutputDir),
+			LibDir:    resolvePath(cwd, defaultLibDir),
+		}}}, nil
+	}
+
+	config, err := loadConfig()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("未指定源文件且找不到 .gophp.yaml")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range serviceFilter {
+		selected[name] = true
+	}
+	matched := make(map[string]bool)
+	proj := &project{Jobs: config.Jobs, Build: config.Build}
+	for _, svc := range config.Services {
+		if len(selected) > 0 && !selected[svc.Name] {
+			continue
+		}
+		matched[svc.Name] = true
+		proj.Services = append(proj.Services, &serviceSource{
+			Source:    svc.Source,
+			Service:   svc.Name,
+			Namespace: svc.Namespace,
+			ClassName: svc.Class,
+			BaseClass: svc.BaseClass,
+			PHPFile:   svc.Output.PHPFile,
+			JSON:      *svc.JSON,
+			Runtime:   svc.Runtime,
+			Preload:   *svc.Preload,
+			Targets:   svc.Targets,
+			Naming:    svc.Naming,
+			OutputDir: svc.Output.Dir,
+			LibDir:    svc.Output.LibDir,
+		})
+	}
+	for _, name := range serviceFilter {
+		if !matched[name] {
+			return nil, fmt.Errorf(".gophp.yaml 中没有名为 %s 的服务", name)
+		}
+	}
+	return proj, nil
+}
+
+// serviceNameFromSource 从源码位置推导服务名
+// 文件取去掉 .go 的文件名，目录取目录名，导入路径取最后一段
+func serviceNameFromSource(source string) string {
+	if strings.HasSuffix(source, ".go") {
+		return strings.TrimSuffix(filepath.Base(source), ".go")
+	}
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		if abs, err := filepath.Abs(source); err == nil {
+			return filepath.Base(abs)
+		}
+	}
+	return path.Base(strings.TrimSuffix(filepath.ToSlash(source), "/"))
+}
+
+// isLocalDir 判断源码位置是否为本地目录
+func isLocalDir(source string) bool {
+	info, err := os.Stat(source)
+	return err == nil && info.IsDir()
+}
+
+// buildTargets 返回传给 go build 的包参数
+// 单文件模式下会附带生成器输出的适配层文件；相对目录需要加上 ./ 前缀，否则会被当作导入路径
+func buildTargets(source, service string) []string {
+	if strings.HasSuffix(source, ".go") {
+		targets := []string{source}
+		shim := filepath.Join(filepath.Dir(source), generator.ShimFileName(service))
+		if _, err := os.Stat(shim); err == nil {
+			targets = append(targets, shim)
+		}
+		return targets
+	}
+	if !isLocalDir(source) || filepath.IsAbs(source) {
+		return []string{source}
+	}
+	if source == "." || source == ".." || strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+		return []string{source}
+	}
+	return []string{"./" + filepath.ToSlash(source)}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const multiServiceConfig = `services:
+  - name: alpha
+    source: alpha.go
+  - name: beta
+    source: beta.go
+  - name: gamma
+    source: gamma.go
+`
+
+// writeConfig 在临时目录中写入 .gophp.yaml 并切换到该目录
+func writeConfig(t *testing.T, config string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, configFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+// setServiceFilter 设置 --service 并在测试结束后恢复
+func setServiceFilter(t *testing.T, names ...string) {
+	t.Helper()
+	old := serviceFilter
+	serviceFilter = names
+	t.Cleanup(func() { serviceFilter = old })
+}
+
+func TestResolveProjectServiceFilter(t *testing.T) {
+	writeConfig(t, multiServiceConfig)
+
+	tests := []struct {
+		name   string
+		filter []string
+		want   string
+	}{
+		{"all services", nil, "alpha,beta,gamma"},
+		{"first", []string{"alpha"}, "alpha"},
+		{"middle", []string{"beta"}, "beta"},
+		{"subset in config order", []string{"gamma", "alpha"}, "alpha,gamma"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setServiceFilter(t, tt.filter...)
+			proj, err := resolveProject(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, svc := range proj.Services {
+				names = append(names, svc.Service)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Fatalf("services = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveProjectUnknownService(t *testing.T) {
+	writeConfig(t, multiServiceConfig)
+	setServiceFilter(t, "beta", "delta")
+	_, err := resolveProject(nil)
+	if err == nil || !strings.Contains(err.Error(), "delta") {
+		t.Fatalf("err = %v, want unknown service delta", err)
+	}
+}
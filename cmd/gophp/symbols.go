@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// noVerify 为 true 时跳过构建后的导出符号检查
+var noVerify bool
+
+// ffiCall 匹配 PHP 服务类中通过 FFI 调用的符号
+var ffiCall = regexp.MustCompile(`\$this->ffi->([A-Za-z_][A-Za-z0-9_]*)\(`)
+
+// ffiMethods 是 \FFI 实例自身的方法，不是共享库中的符号
+var ffiMethods = map[string]bool{
+	"new": true, "cast": true, "type": true, "typeof": true, "arrayType": true, "addr": true,
+	"sizeof": true, "alignof": true, "memcpy": true, "memcmp": true, "memset": true,
+	"string": true, "isNull": true, "free": true,
+}
+
+// verifyExports 检查共享库的导出符号表：symbols（生成器解析得到的符号）与 PHP 服务类中调用的符号
+// 都必须由共享库导出，否则返回列出缺失符号的错误；共享库多导出的 //export 函数作为警告返回，
+// wrapped 中被适配函数包装的原始导出函数不计入警告
+func verifyExports(library, goos string, symbols, wrapped []string, phpFile string) ([]string, error) {
+	exported, err := librarySymbols(library, goos)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 的符号表失败：%w", library, err)
+	}
+	php, err := os.ReadFile(phpFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return checkExports(exported, symbols, wrapped, php, displayPath(phpFile), displayPath(library))
+}
+
+// checkExports 对比共享库导出的符号与期望的符号，php 为 PHP 服务类的内容
+func checkExports(exported map[string]bool, symbols, wrapped []string, php []byte, phpFile, library string) ([]string, error) {
+	missing := make(map[string][]string)
+	expected := make(map[string]bool)
+	for _, symbol := range symbols {
+		expected[symbol] = true
+		if !exported[symbol] {
+			missing[symbol] = append(missing[symbol], "生成器解析得到")
+		}
+	}
+	for _, symbol := range wrapped {
+		expected[symbol] = true
+	}
+	called := make(map[string]bool)
+	for _, m := range ffiCall.FindAllSubmatch(php, -1) {
+		symbol := string(m[1])
+		if ffiMethods[symbol] || called[symbol] {
+			continue
+		}
+		called[symbol] = true
+		if !exported[symbol] {
+			missing[symbol] = append(missing[symbol], fmt.Sprintf("%s 调用", phpFile))
+		}
+	}
+
+	var warnings []string
+	for _, symbol := range goExports(exported) {
+		if !expected[symbol] && !called[symbol] {
+			warnings = append(warnings, fmt.Sprintf("共享库导出了 %s，但 PHP 服务类中没有对应的方法", symbol))
+		}
+	}
+
+	if len(missing) == 0 {
+		return warnings, nil
+	}
+	names := make([]string, 0, len(missing))
+	for symbol := range missing {
+		names = append(names, symbol)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "共享库 %s 缺少以下导出符号：", library)
+	for _, symbol := range names {
+		fmt.Fprintf(&sb, "\n  - %s（%s）", symbol, strings.Join(missing[symbol], "，"))
+	}
+	sb.WriteString("\n源码的导出有变化时请先运行 gophpffi generate")
+	return warnings, errors.New(sb.String())
+}
+
+// goExports 从共享库导出的符号中挑出 //export 函数并排序
+// cgo 为每个导出函数生成 _cgoexp_<hash>_<Name>；导出表中没有这些符号时（PE），按名称排除 cgo 与运行时的内部符号
+func goExports(exported map[string]bool) []string {
+	var names []string
+	for symbol := range exported {
+		if !strings.HasPrefix(symbol, "_cgoexp_") {
+			continue
+		}
+		if _, name, ok := strings.Cut(strings.TrimPrefix(symbol, "_cgoexp_"), "_"); ok && exported[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		for symbol := range exported {
+			if !strings.HasPrefix(symbol, "_") && !strings.HasPrefix(symbol, "x_cgo") && !strings.HasPrefix(symbol, "crosscall") {
+				names = append(names, symbol)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// librarySymbols 返回共享库导出的函数符号，按目标系统选择 ELF、PE 或 Mach-O 格式
+func librarySymbols(library, goos string) (map[string]bool, error) {
+	switch goos {
+	case "windows":
+		return peSymbols(library)
+	case "darwin", "ios":
+		return machoSymbols(library)
+	default:
+		return elfSymbols(library)
+	}
+}
+
+// elfSymbols 返回 ELF 动态符号表中已定义的全局函数
+func elfSymbols(library string) (map[string]bool, error) {
+	f, err := elf.Open(library)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	syms, err := f.DynamicSymbols()
+	if err != nil {
+		return nil, err
+	}
+	exported := make(map[string]bool)
+	for _, s := range syms {
+		bind := elf.ST_BIND(s.Info)
+		if s.Section == elf.SHN_UNDEF || elf.ST_TYPE(s.Info) != elf.STT_FUNC || (bind != elf.STB_GLOBAL && bind != elf.STB_WEAK) {
+			continue
+		}
+		exported[s.Name] = true
+	}
+	return exported, nil
+}
+
+// machoSymbols 返回 Mach-O 符号表中已定义的外部符号（去掉 C 符号的前导下划线）
+func machoSymbols(library string) (map[string]bool, error) {
+	f, err := macho.Open(library)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if f.Symtab == nil {
+		return nil, errors.New("没有符号表")
+	}
+	const (
+		nExt  = 0x01
+		nType = 0x0e
+		nSect = 0x0e
+	)
+	exported := make(map[string]bool)
+	for _, s := range f.Symtab.Syms {
+		if s.Type&nExt == 0 || s.Type&nType != nSect {
+			continue
+		}
+		exported[strings.TrimPrefix(s.Name, "_")] = true
+	}
+	return exported, nil
+}
+
+// peSymbols 返回 PE 导出表中的符号名（debug/pe 不解析导出表，这里直接读取导出目录）
+func peSymbols(library string) (map[string]bool, error) {
+	f, err := pe.Open(library)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dirs []pe.DataDirectory
+	switch oh := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		dirs = oh.DataDirectory[:min(oh.NumberOfRvaAndSizes, uint32(len(oh.DataDirectory)))]
+	case *pe.OptionalHeader64:
+		dirs = oh.DataDirectory[:min(oh.NumberOfRvaAndSizes, uint32(len(oh.DataDirectory)))]
+	}
+	exported := make(map[string]bool)
+	if len(dirs) <= pe.IMAGE_DIRECTORY_ENTRY_EXPORT || dirs[pe.IMAGE_DIRECTORY_ENTRY_EXPORT].VirtualAddress == 0 {
+		return exported, nil
+	}
+
+	// rva 返回虚拟地址所在节中从该地址开始的数据
+	rva := func(addr uint32) ([]byte, error) {
+		for _, s := range f.Sections {
+			if addr >= s.VirtualAddress && addr < s.VirtualAddress+s.VirtualSize {
+				data, err := s.Data()
+				if err != nil {
+					return nil, err
+				}
+				if off := addr - s.VirtualAddress; int(off) < len(data) {
+					return data[off:], nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("无效的 RVA 0x%x", addr)
+	}
+
+	dir, err := rva(dirs[pe.IMAGE_DIRECTORY_ENTRY_EXPORT].VirtualAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(dir) < 40 {
+		return nil, errors.New("导出目录不完整")
+	}
+	count := binary.LittleEndian.Uint32(dir[24:])
+	names, err := rva(binary.LittleEndian.Uint32(dir[32:]))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(names)) < uint64(count)*4 {
+		return nil, errors.New("导出名称表不完整")
+	}
+	for i := uint32(0); i < count; i++ {
+		name, err := rva(binary.LittleEndian.Uint32(names[i*4:]))
+		if err != nil {
+			return nil, err
+		}
+		if end := bytes.IndexByte(name, 0); end >= 0 {
+			name = name[:end]
+		}
+		exported[string(name)] = true
+	}
+	return exported, nil
+}
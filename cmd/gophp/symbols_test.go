@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wuwuseo/gophpffi/generator"
+)
+
+const shimmedService = `package main
+
+import "C"
+
+import "errors"
+
+//export Echo
+func Echo(s string) string { return s }
+
+//export Divide
+func Divide(a, b int) (int, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+
+//export Add
+func Add(a, b int) int { return a + b }
+
+func main() {}
+`
+
+// librarySymbolsOf 模拟 cgo 共享库的导出表：每个 //export 函数都带有 _cgoexp_ 符号
+func librarySymbolsOf(names ...string) map[string]bool {
+	exported := map[string]bool{"_cgo_panic": true, "crosscall2": true}
+	for _, name := range names {
+		exported[name] = true
+		exported["_cgoexp_0123456789ab_"+name] = true
+	}
+	return exported
+}
+
+func TestCheckExportsShimmedService(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "calc.go")
+	if err := os.WriteFile(source, []byte(shimmedService), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := generator.Generate(generator.Options{Source: source, ServiceName: "Calc", OutputDir: filepath.Join(dir, "dist")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	php, err := os.ReadFile(result.PHPFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrapped := strings.Join(result.Wrapped, ",")
+	if wrapped != "Echo,Divide" {
+		t.Fatalf("Wrapped = %s, want Echo,Divide", wrapped)
+	}
+
+	// 共享库导出生成器期望的符号、被包装的原始函数以及一个 PHP 不使用的函数
+	all := append(append([]string{}, result.Symbols...), result.Wrapped...)
+	warnings, err := checkExports(librarySymbolsOf(append(all, "Stray")...), result.Symbols, result.Wrapped, php, "CalcService.php", "calc.so")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "Stray") {
+		t.Fatalf("warnings = %q, want a single warning about Stray", warnings)
+	}
+
+	// 缺少适配函数时返回错误
+	var partial []string
+	for _, symbol := range all {
+		if symbol != "Calc_Echo" {
+			partial = append(partial, symbol)
+		}
+	}
+	_, err = checkExports(librarySymbolsOf(partial...), result.Symbols, result.Wrapped, php, "CalcService.php", "calc.so")
+	if err == nil || !strings.Contains(err.Error(), "Calc_Echo") {
+		t.Fatalf("err = %v, want missing Calc_Echo", err)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BuildTarget 描述一个交叉编译目标
+// 配置文件中既可以写成 "linux/arm64"，也可以写成带 cc/cxx 与构建参数（同 BuildProfile）的映射：
+//
+//	targets:
+//	  - linux/amd64
+//	  - platform: linux/arm64
+//	    cc: aarch64-linux-gnu-gcc
+//	    cxx: aarch64-linux-gnu-g++
+//	    env:
+//	      CGO_CFLAGS: -march=armv8-a
+type BuildTarget struct {
+	OS   string
+	Arch string
+	CC   string // 该目标使用的 C 编译器（为空时沿用环境变量 CC）
+	CXX  string // 该目标使用的 C++ 编译器（为空时沿用环境变量 CXX）
+	// Overrides 是该目标叠加在构建配置档之上的参数、标签与环境变量
+	Overrides BuildProfile
+}
+
+// Platform 返回 os/arch 形式的目标名
+func (t BuildTarget) Platform() string {
+	return t.OS + "/" + t.Arch
+}
+
+// UnmarshalYAML 支持字符串与映射两种写法
+func (t *BuildTarget) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		parsed, err := parseTarget(node.Value)
+		if err != nil {
+			return err
+		}
+		*t = parsed
+		return nil
+	}
+
+	var raw struct {
+		Platform     string `yaml:"platform"`
+		CC           string `yaml:"cc"`
+		CXX          string `yaml:"cxx"`
+		BuildProfile `yaml:",inline"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	parsed, err := parseTarget(raw.Platform)
+	if err != nil {
+		return err
+	}
+	parsed.CC, parsed.CXX = raw.CC, raw.CXX
+	parsed.Overrides = raw.BuildProfile
+	*t = parsed
+	return nil
+}
+
+// parseTarget 解析 os/arch 形式的目标
+func parseTarget(platform string) (BuildTarget, error) {
+	goos, goarch, ok := strings.Cut(strings.TrimSpace(platform), "/")
+	if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+		return BuildTarget{}, fmt.Errorf("无效的构建目标 %q，应为 os/arch 形式（例如 linux/arm64）", platform)
+	}
+	return BuildTarget{OS: goos, Arch: goarch}, nil
+}
+
+// resolveTargets 确定本次构建的目标列表
+// --target 优先于配置文件中的 targets（同一平台沿用配置中的 cc/cxx 与构建参数），两者都为空时构建当前平台
+func resolveTargets(flagTargets []string, configured []BuildTarget) ([]BuildTarget, error) {
+	if len(flagTargets) == 0 {
+		if len(configured) > 0 {
+			return configured, nil
+		}
+		return []BuildTarget{{OS: runtime.GOOS, Arch: runtime.GOARCH}}, nil
+	}
+
+	var targets []BuildTarget
+	for _, platform := range flagTargets {
+		target, err := parseTarget(platform)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range configured {
+			if c.Platform() == target.Platform() {
+				target.CC, target.CXX, target.Overrides = c.CC, c.CXX, c.Overrides
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// libExtension 返回目标系统的共享库扩展名
+func libExtension(goos string) string {
+	switch goos {
+	case "windows":
+		return "dll"
+	case "darwin", "ios":
+		return "dylib"
+	default:
+		return "so"
+	}
+}
+
+// buildEnv 返回构建目标时使用的环境变量
+func buildEnv(environ []string, target BuildTarget) []string {
+	env := append([]string{}, environ...)
+	env = append(env, "GOOS="+target.OS, "GOARCH="+target.Arch, "CGO_ENABLED=1")
+	if target.CC != "" {
+		env = append(env, "CC="+target.CC)
+	}
+	if target.CXX != "" {
+		env = append(env, "CXX="+target.CXX)
+	}
+	return env
+}
@@ -0,0 +1,185 @@
+package generator
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// goPrologue 是与 cgo 导出头文件等价的基础类型定义
+// GoInt/GoUint 使用 intptr_t/uintptr_t，在 32 位与 64 位平台上都与 Go 的 int/uint 宽度一致
+const goPrologue = `typedef signed char GoInt8;
+typedef unsigned char GoUint8;
+typedef short GoInt16;
+typedef unsigned short GoUint16;
+typedef int GoInt32;
+typedef unsigned int GoUint32;
+typedef long long GoInt64;
+typedef unsigned long long GoUint64;
+typedef intptr_t GoInt;
+typedef uintptr_t GoUint;
+typedef size_t GoUintptr;
+typedef float GoFloat32;
+typedef double GoFloat64;
+typedef struct { const char *p; ptrdiff_t n; } GoString;
+typedef void *GoMap;
+typedef void *GoChan;
+typedef struct { void *t; void *v; } GoInterface;
+typedef struct { void *data; GoInt len; GoInt cap; } GoSlice;
+`
+
+// cBasicTypes 将 Go 基础类型映射为 cgo 导出头文件中的 C 类型
+var cBasicTypes = map[types.BasicKind]string{
+	types.Int:           "GoInt",
+	types.Int8:          "GoInt8",
+	types.Int16:         "GoInt16",
+	types.Int32:         "GoInt32",
+	types.Int64:         "GoInt64",
+	types.Uint:          "GoUint",
+	types.Uint8:         "GoUint8",
+	types.Uint16:        "GoUint16",
+	types.Uint32:        "GoUint32",
+	types.Uint64:        "GoUint64",
+	types.Uintptr:       "GoUintptr",
+	types.Float32:       "GoFloat32",
+	types.Float64:       "GoFloat64",
+	types.Bool:          "GoUint8",
+	types.String:        "GoString",
+	types.UnsafePointer: "void*",
+}
+
+// goCType 返回参数或返回值在 cgo 导出头文件中的 C 类型
+func goCType(p Param) string {
+	if p.GoType == nil {
+		// 无法解析的类型通常是 C.xxx（类型检查使用 FakeImportC）
+		t := strings.ReplaceAll(p.Type, " ", "")
+		stars := strings.Count(t, "*")
+		t = strings.TrimLeft(t, "*")
+		if strings.HasPrefix(t, "C.") {
+			return strings.TrimPrefix(t, "C.") + strings.Repeat("*", stars)
+		}
+		return "void*"
+	}
+	switch t := p.GoType.Underlying().(type) {
+	case *types.Basic:
+		if c, ok := cBasicTypes[t.Kind()]; ok {
+			return c
+		}
+	case *types.Slice:
+		return "GoSlice"
+	case *types.Map:
+		return "GoMap"
+	case *types.Chan:
+		return "GoChan"
+	case *types.Interface:
+		return "GoInterface"
+	case *types.Pointer:
+		if basic, ok := t.Elem().Underlying().(*types.Basic); ok {
+			if c, ok := cBasicTypes[basic.Kind()]; ok && basic.Kind() != types.UnsafePointer {
+				return c + "*"
+			}
+		}
+	}
+	return "void*"
+}
+
+// cFuncDeclaration 返回 PHP 实际调用的 FFI 符号的 C 声明
+// 多返回值与 cgo 一致声明为 struct <Symbol>_return
+func cFuncDeclaration(exp ExportedFunc, service string) string {
+	symbol := ffiSymbol(exp, service)
+	shim := needsShim(exp)
+	if exp.JSON {
+		exp = jsonBridge(exp)
+	}
+
+	var params []string
+	if exp.Receiver != nil {
+		params = append(params, "GoUintptr handle")
+	}
+	for i, p := range exp.Params {
+		cType := goCType(p)
+		if isStructValue(exp, p) {
+			cType = structCName(p.Struct, service)
+		}
+		params = append(params, fmt.Sprintf("%s p%d", cType, i))
+	}
+	if len(params) == 0 {
+		params = append(params, "void")
+	}
+
+	// 与 generateShim 中的返回值转换保持一致
+	var results []string
+	for _, r := range valueResults(exp) {
+		switch {
+		case !shim:
+			results = append(results, goCType(r))
+		case r.Handle != nil:
+			results = append(results, "GoUintptr")
+		case isStructValue(exp, r):
+			results = append(results, structCName(r.Struct, service))
+		case isGoString(r), isGoBytes(r):
+			results = append(results, "char*", "size_t")
+		case isCopyableSlice(r):
+			results = append(results, "void*", "size_t")
+		default:
+			results = append(results, goCType(r))
+		}
+	}
+	if returnsError(exp) {
+		results = append(results, "char*")
+	}
+
+	var sb strings.Builder
+	signature := fmt.Sprintf("%s(%s);\n", symbol, strings.Join(params, ", "))
+	switch len(results) {
+	case 0:
+		sb.WriteString("void " + signature)
+	case 1:
+		sb.WriteString(results[0] + " " + signature)
+	default:
+		sb.WriteString(fmt.Sprintf("struct %s_return {\n", symbol))
+		for i, r := range results {
+			sb.WriteString(fmt.Sprintf("\t%s r%d;\n", r, i))
+		}
+		sb.WriteString("};\n")
+		sb.WriteString(fmt.Sprintf("struct %s_return %s", symbol, signature))
+	}
+	return sb.String()
+}
+
+// exportedSymbols 返回共享库需要导出的 C 符号，与 generateCDeclarations 声明的函数一致
+func exportedSymbols(pkg *parsedPackage, service string) []string {
+	symbols := []string{freeFuncName(service)}
+	for _, exp := range pkg.funcs() {
+		symbols = append(symbols, ffiSymbol(exp, service))
+	}
+	return symbols
+}
+
+// wrappedExports 返回带 //export 指令、但 PHP 通过适配函数调用的函数名
+// 这些函数仍由共享库导出，构建后的符号检查不应将其视为多余的导出
+func wrappedExports(pkg *parsedPackage) []string {
+	var names []string
+	for _, exp := range pkg.Exports {
+		if !exp.Shim && !exp.JSON && needsShim(exp) {
+			names = append(names, exp.Name)
+		}
+	}
+	return names
+}
+
+// generateCDeclarations 生成供 FFI::cdef 使用的完整 C 声明（不含任何预处理指令）
+func generateCDeclarations(pkg *parsedPackage, opts generateOptions) string {
+	var sb strings.Builder
+	sb.WriteString(goPrologue)
+	if len(pkg.Structs) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(generateStructCTypes(pkg.Structs, opts.ServiceName))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("void %s(void* p);\n", freeFuncName(opts.ServiceName)))
+	for _, exp := range pkg.funcs() {
+		sb.WriteString(cFuncDeclaration(exp, opts.ServiceName))
+	}
+	return sb.String()
+}
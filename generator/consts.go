@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// EnumDef 表示一个枚举：包内声明的、底层为基础类型的命名类型及其导出常量
+type EnumDef struct {
+	Name    string         // Go 类型名
+	GoType  string         // 底层类型，例如 int、string
+	Comment string         // 单行化的文档注释
+	Values  []ConstDef     // 该类型的导出常量，按声明顺序排列
+	Pos     token.Position // 类型声明在源码中的位置
+}
+
+// ConstDef 表示一个导出常量
+type ConstDef struct {
+	Name    string         // 常量名
+	GoType  string         // 常量类型（无类型常量为 untyped int 等）
+	Value   any            // 常量值：int64、float64、string 或 bool，超出 int64 的整数为十进制字符串
+	Comment string         // 单行化的文档注释
+	Pos     token.Position // 常量声明在源码中的位置
+}
+
+// collectConsts 提取包中的导出常量：类型为包内命名类型的常量按类型归入枚举，其余常量单独返回
+func collectConsts(fset *token.FileSet, files []*ast.File, info *types.Info) ([]EnumDef, []ConstDef) {
+	// 命名类型的文档注释
+	typeDocs := make(map[*types.TypeName]*ast.CommentGroup)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if obj, ok := info.Defs[ts.Name].(*types.TypeName); ok {
+					typeDocs[obj] = doc
+				}
+			}
+		}
+	}
+
+	enums := make(map[*types.TypeName]*EnumDef)
+	var order []*types.TypeName
+	var consts []ConstDef
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				doc := vs.Doc
+				if doc == nil {
+					doc = vs.Comment
+				}
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				comment, _ := docText(doc)
+				for _, name := range vs.Names {
+					obj, ok := info.Defs[name].(*types.Const)
+					if !name.IsExported() || !ok || obj.Val().Kind() == constant.Unknown {
+						continue
+					}
+					c := ConstDef{
+						Name:    name.Name,
+						GoType:  obj.Type().String(),
+						Value:   constValue(obj.Val()),
+						Comment: comment,
+						Pos:     fset.Position(name.Pos()),
+					}
+
+					named, ok := obj.Type().(*types.Named)
+					if !ok || named.Obj().Pkg() != obj.Pkg() {
+						consts = append(consts, c)
+						continue
+					}
+					c.GoType = named.Obj().Name()
+					tn := named.Obj()
+					enum := enums[tn]
+					if enum == nil {
+						enumComment, _ := docText(typeDocs[tn])
+						enum = &EnumDef{
+							Name:    tn.Name(),
+							GoType:  named.Underlying().String(),
+							Comment: enumComment,
+							Pos:     fset.Position(tn.Pos()),
+						}
+						enums[tn] = enum
+						order = append(order, tn)
+					}
+					enum.Values = append(enum.Values, c)
+				}
+			}
+		}
+	}
+
+	// 按类型声明的位置排序，使结果与文件遍历顺序无关
+	sort.SliceStable(order, func(i, j int) bool { return order[i].Pos() < order[j].Pos() })
+	result := make([]EnumDef, 0, len(order))
+	for _, tn := range order {
+		result = append(result, *enums[tn])
+	}
+	return result, consts
+}
+
+// constValue 将常量值转换为可以写入 JSON 的值
+func constValue(v constant.Value) any {
+	switch v.Kind() {
+	case constant.Bool:
+		return constant.BoolVal(v)
+	case constant.String:
+		return constant.StringVal(v)
+	case constant.Int:
+		if i, exact := constant.Int64Val(v); exact {
+			return i
+		}
+		return v.ExactString()
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return f
+	}
+	return v.ExactString()
+}
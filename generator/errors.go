@@ -0,0 +1,58 @@
+package generator
+
+import "strings"
+
+// hasErrorResults 判断是否存在可能返回错误的导出函数
+func hasErrorResults(exports []ExportedFunc) bool {
+	for _, exp := range exports {
+		if canThrow(exp) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateErrorHelper 生成检查 Go 错误并抛出异常的私有方法
+// 错误信息由适配函数以 C 字符串返回，读取后立即释放
+func generateErrorHelper() string {
+	var sb strings.Builder
+	sb.WriteString("    /**\n")
+	sb.WriteString("     * 检查 Go 返回的错误信息，非空时释放内存并抛出异常\n")
+	sb.WriteString("     * @param string $function\n")
+	sb.WriteString("     * @param \\FFI\\CData|null $error\n")
+	sb.WriteString("     * @return void\n")
+	sb.WriteString("     * @throws GoServiceException\n")
+	sb.WriteString("     */\n")
+	sb.WriteString("    private function throwIfGoError(string $function, $error): void {\n")
+	sb.WriteString("        if ($error === null) {\n")
+	sb.WriteString("            return;\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("        throw new GoServiceException($function, $this->takeCString($error));\n")
+	sb.WriteString("    }\n")
+	return sb.String()
+}
+
+// generateExceptionClass 生成表示 Go error 的 PHP 异常类
+func generateExceptionClass() string {
+	return `/**
+ * Exception thrown when a Go function returns a non-nil error
+ */
+class GoServiceException extends \RuntimeException {
+    /** @var string */
+    private string $goFunction;
+
+    public function __construct(string $goFunction, string $message) {
+        parent::__construct($message);
+        $this->goFunction = $goFunction;
+    }
+
+    /**
+     * 返回出错的 Go 函数名
+     * @return string
+     */
+    public function getGoFunction(): string {
+        return $this->goFunction;
+    }
+}
+`
+}
@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+// RuleDirectExport 是 Fix 额外检查的规则：签名可以直接 //export 的 //gophp:export 函数
+const RuleDirectExport = "direct-export"
+
+// FixedFile 描述 Fix 对单个源文件的修改
+type FixedFile struct {
+	Filename string       // 源文件（绝对路径）
+	Original []byte       // 修改前的内容
+	Fixed    []byte       // 修改后的内容（原文件已经 gofmt 格式化时同样格式化）
+	Applied  []Diagnostic // 已修复的问题
+}
+
+// FixResult 描述一次 Fix 的结果
+type FixResult struct {
+	Package   string       // 包的导入路径
+	Files     []FixedFile  // 有修改的文件，按文件名排序
+	Remaining []Diagnostic // 无法自动修复的问题
+}
+
+// Fixed 返回已修复的问题总数
+func (r FixResult) Fixed() int {
+	n := 0
+	for _, f := range r.Files {
+		n += len(f.Applied)
+	}
+	return n
+}
+
+// Fix 自动修复 Lint 报告的常见导出问题，只使用 Options 中的 Source 与 Tags
+// 可以修复的问题：缺少 import "C"、缺少 func main、//export 名称与函数名不一致、
+// 指令与声明之间的空行，以及签名可以直接 //export 的 //gophp:export 函数
+// Fix 不写入任何文件，由调用方决定写回还是只显示差异
+func Fix(options Options) (FixResult, error) {
+	l, pkg, err := lintSource(options)
+	if err != nil {
+		return FixResult{}, err
+	}
+	for _, file := range l.files {
+		if !ast.IsGenerated(file) {
+			l.checkDirectExports(file)
+		}
+	}
+	l.sortDiagnostics()
+
+	result := FixResult{Package: pkg.ImportPath}
+	byFile := make(map[string][]Diagnostic)
+	for _, d := range l.diags {
+		if len(d.edits) == 0 {
+			result.Remaining = append(result.Remaining, d)
+			continue
+		}
+		byFile[d.Pos.Filename] = append(byFile[d.Pos.Filename], d)
+	}
+
+	filenames := make([]string, 0, len(byFile))
+	for filename := range byFile {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		fixed, err := applyFixes(filename, l.src[filename], byFile[filename])
+		if err != nil {
+			return FixResult{}, err
+		}
+		result.Remaining = append(result.Remaining, fixed.skipped...)
+		if len(fixed.Applied) > 0 {
+			result.Files = append(result.Files, fixed.FixedFile)
+		}
+	}
+	return result, nil
+}
+
+// checkDirectExports 查找签名不含 DTO 等 cgo 无法导出的类型、却使用 //gophp:export 的函数，
+// 把指令替换为 //export（函数改为直接导出，返回 string/error 等仍由适配层包装）
+// 指定了与函数名不同的导出名的函数保持不变，以免改变 PHP 方法名
+func (l *linter) checkDirectExports(file *ast.File) {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Doc == nil {
+			continue
+		}
+		if _, exported := findExportName(fn.Doc); exported {
+			continue
+		}
+		var directive *ast.Comment
+		for _, c := range fn.Doc.List {
+			body, ok := strings.CutPrefix(c.Text, gophpDirective)
+			if !ok {
+				continue
+			}
+			if key, value, _ := strings.Cut(body, " "); key == "export" {
+				if name := strings.TrimSpace(value); name == "" || name == fn.Name.Name {
+					directive = c
+				}
+			}
+		}
+		if directive == nil {
+			continue
+		}
+		probe := &linter{fset: l.fset, info: l.info}
+		probe.checkSignature(fn)
+		if len(probe.diags) > 0 {
+			continue
+		}
+
+		d := l.report(directive.Pos(), SeverityWarning, RuleDirectExport, fmt.Sprintf("replace the directive with //export %s", fn.Name.Name),
+			"%s has a cgo-compatible signature and can be exported with //export", fn.Name.Name)
+		d.edits = []textEdit{l.edit(directive.Pos(), directive.End(), exportDirective+fn.Name.Name)}
+		if !importsC(file) {
+			d.edits = append(d.edits, l.edit(file.Name.End(), file.Name.End(), "\n\nimport \"C\""))
+		}
+	}
+}
+
+// fixedFile 是 applyFixes 的结果，skipped 为修改区域与其他修复重叠而未应用的问题
+type fixedFile struct {
+	FixedFile
+	skipped []Diagnostic
+}
+
+// applyFixes 把诊断附带的修改应用到源文件
+// 多个诊断中相同的修改（例如同一处 import "C"）只应用一次，与已应用的修改重叠的诊断留待下次修复
+func applyFixes(filename string, src []byte, diags []Diagnostic) (fixedFile, error) {
+	result := fixedFile{FixedFile: FixedFile{Filename: filename, Original: src}}
+	var edits []textEdit
+	overlaps := func(e textEdit, pending []textEdit) (dup, conflict bool) {
+		for _, a := range append(edits[:len(edits):len(edits)], pending...) {
+			if a == e {
+				return true, false
+			}
+			if e.start < a.end && a.start < e.end || e.start == a.start {
+				return false, true
+			}
+		}
+		return false, false
+	}
+	for _, d := range diags {
+		var pending []textEdit
+		ok := true
+		for _, e := range d.edits {
+			dup, conflict := overlaps(e, pending)
+			if conflict {
+				ok = false
+				break
+			}
+			if !dup {
+				pending = append(pending, e)
+			}
+		}
+		if !ok {
+			result.skipped = append(result.skipped, d)
+			continue
+		}
+		edits = append(edits, pending...)
+		result.Applied = append(result.Applied, d)
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		buf.Write(src[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(src[last:])
+	result.Fixed = buf.Bytes()
+
+	// 只有原文件已经格式化时才格式化结果，避免差异中出现与修复无关的改动
+	if formatted, err := format.Source(src); err == nil && bytes.Equal(formatted, src) {
+		fixed, err := format.Source(result.Fixed)
+		if err != nil {
+			return fixedFile{}, fmt.Errorf("format %s: %w", filename, err)
+		}
+		result.Fixed = fixed
+	}
+	return result, nil
+}
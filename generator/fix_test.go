@@ -0,0 +1,121 @@
+package generator
+
+import "testing"
+
+func TestApplyFixes(t *testing.T) {
+	// insert 与 replace 构造针对 src 的修改；只有已经 gofmt 格式化的 Go 源码的结果才会被格式化
+	insert := func(at int, text string) textEdit { return textEdit{start: at, end: at, text: text} }
+	replace := func(start, end int, text string) textEdit { return textEdit{start: start, end: end, text: text} }
+	diag := func(rule string, edits ...textEdit) Diagnostic { return Diagnostic{Rule: rule, edits: edits} }
+
+	tests := []struct {
+		name    string
+		src     string
+		diags   []Diagnostic
+		want    string
+		applied []string
+		skipped []string
+	}{
+		{
+			name:    "empty source",
+			src:     "",
+			diags:   []Diagnostic{diag("a", insert(0, "x\n"))},
+			want:    "x\n",
+			applied: []string{"a"},
+		},
+		{
+			name:    "no trailing newline",
+			src:     "abc",
+			diags:   []Diagnostic{diag("a", insert(3, "\ndef"))},
+			want:    "abc\ndef",
+			applied: []string{"a"},
+		},
+		{
+			name:    "edits applied in source order",
+			src:     "one two three",
+			diags:   []Diagnostic{diag("a", replace(8, 13, "3")), diag("b", replace(0, 3, "1"))},
+			want:    "1 two 3",
+			applied: []string{"a", "b"},
+		},
+		{
+			name:    "adjacent edits",
+			src:     "abcdef",
+			diags:   []Diagnostic{diag("a", replace(0, 3, "X")), diag("b", replace(3, 6, "Y"))},
+			want:    "XY",
+			applied: []string{"a", "b"},
+		},
+		{
+			name:    "shared edit applied once",
+			src:     "package main\n",
+			diags:   []Diagnostic{diag("a", insert(12, "\nimport \"C\"")), diag("b", insert(12, "\nimport \"C\""), insert(13, "\nfunc main() {}\n"))},
+			want:    "package main\n\nimport \"C\"\n\nfunc main() {}\n",
+			applied: []string{"a", "b"},
+		},
+		{
+			name:    "duplicate edit within one diagnostic",
+			src:     "a b",
+			diags:   []Diagnostic{diag("a", insert(1, "-"), insert(1, "-"))},
+			want:    "a- b",
+			applied: []string{"a"},
+		},
+		{
+			name:    "overlapping edit skipped",
+			src:     "abcdef",
+			diags:   []Diagnostic{diag("a", replace(1, 4, "X")), diag("b", replace(3, 5, "Y"))},
+			want:    "aXef",
+			applied: []string{"a"},
+			skipped: []string{"b"},
+		},
+		{
+			name:    "different insertions at one position",
+			src:     "ab",
+			diags:   []Diagnostic{diag("a", insert(1, "X")), diag("b", insert(1, "Y"))},
+			want:    "aXb",
+			applied: []string{"a"},
+			skipped: []string{"b"},
+		},
+		{
+			name:    "conflicting diagnostic applies none of its edits",
+			src:     "abcdef",
+			diags:   []Diagnostic{diag("a", replace(2, 4, "X")), diag("b", insert(0, "Y"), replace(3, 4, "Z"))},
+			want:    "abXef",
+			applied: []string{"a"},
+			skipped: []string{"b"},
+		},
+	}
+	rules := func(diags []Diagnostic) []string {
+		var names []string
+		for _, d := range diags {
+			names = append(names, d.Rule)
+		}
+		return names
+	}
+	equal := func(a, b []string) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyFixes("x.txt", []byte(tt.src), tt.diags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got.Fixed) != tt.want {
+				t.Errorf("Fixed = %q, want %q", got.Fixed, tt.want)
+			}
+			if names := rules(got.Applied); !equal(names, tt.applied) {
+				t.Errorf("Applied = %v, want %v", names, tt.applied)
+			}
+			if names := rules(got.skipped); !equal(names, tt.skipped) {
+				t.Errorf("skipped = %v, want %v", names, tt.skipped)
+			}
+		})
+	}
+}
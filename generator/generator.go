@@ -0,0 +1,686 @@
+// Package generator 解析带有 //export 与 //gophp: 指令的 Go 源码，
+// 生成 PHP FFI 服务类以及随共享库一起编译的 Go 适配层。
+// gophpffi 的 generate 命令通过 Generate 在进程内调用，其他 Go 工具也可以直接嵌入。
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportedFunc 表示一个导出的 Go 函数
+type ExportedFunc struct {
+	Name        string            // //export 指令中声明的导出名
+	GoName      string            // Go 源码中的函数名
+	PHPName     string            // PHP 方法名（由命名策略或 //gophp:name 指令决定）
+	ResultClass string            // 多返回值结果类的 PHP 类名
+	Comment     string            // 单行化的文档注释（用于 PHPDoc）
+	Doc         string            // 完整的文档注释（保留换行，不含指令）
+	Signature   string            // 规范化后的函数签名
+	ReturnType  string            // 返回类型（无返回值时为 void）
+	Params      []Param           // 参数列表
+	Results     []Param           // 返回值列表
+	Pos         token.Position    // 函数声明在源码中的位置
+	Directives  map[string]string // //gophp:key value 形式的生成器指令
+	JSON        bool              // 复杂参数与返回值是否通过 JSON 桥接
+	Shim        bool              // 是否由 //gophp:export 声明（始终通过适配函数导出）
+	Receiver    *HandleDef        // 句柄方法的接收者类型，普通函数为 nil
+	Release     bool              // 是否为释放句柄的函数
+}
+
+// Param 表示一个函数参数或返回值
+type Param struct {
+	Name   string     // 参数名（匿名参数会自动生成 argN / resultN）
+	Type   string     // 类型字符串（类型检查成功时为解析后的类型，否则为源码表达式）
+	GoType types.Type // 类型检查得到的类型，无法解析时为 nil
+	Struct *StructDef // 类型为 //gophp:struct 结构体时对应的 DTO 定义
+	Handle *HandleDef // 构造函数返回的句柄类型
+}
+
+// generateOptions 代码生成选项
+type generateOptions struct {
+	ServiceName string // 服务名
+	SourceDir   string // 源码目录（Go 适配层文件输出位置）
+	PackageName string // 源码包名
+	Namespace   string // PHP 命名空间
+	ClassName   string // PHP 服务类名（不含命名空间）
+	BaseClass   string // 服务类的父类（完全限定名），为空时不继承
+	PHPFile     string // PHP 服务类文件路径
+	OutputDir   string // PHP 文件输出目录（PHPFile 所在目录）
+	LibDir      string // 共享库输出目录（PHP 按其相对 OutputDir 的位置查找库）
+	ResultMode  string // 多返回值的默认映射方式（array 或 class）
+	JSON        bool   // 服务级 JSON 桥接模式
+	Runtime     string // PHP 运行时模式（library 或 embedded）
+	Preload     bool   // 是否生成 opcache 预加载脚本（仅 embedded 运行时）
+}
+
+// Options 描述一次代码生成
+type Options struct {
+	Source      string   // Go 源文件、包目录或导入路径（相对路径基于当前工作目录）
+	ServiceName string   // 服务名，为空时取文件名或目录名
+	Namespace   string   // PHP 命名空间模板，为空时为 DefaultNamespace（模板语法见 NameData）
+	ClassName   string   // PHP 服务类名模板，为空时为 DefaultClassName
+	BaseClass   string   // 服务类的父类，library 运行时默认为 DefaultBaseClass，embedded 运行时默认不继承
+	PHPFile     string   // PHP 服务类文件路径模板，相对路径基于 OutputDir，为空时为 <OutputDir>/<ClassName>.php
+	Tags        []string // 构建标签
+	ResultMode  string   // 多返回值的默认映射方式：ResultModeArray（默认）或 ResultModeClass
+	JSON        bool     // 通过 JSON 桥接 map/结构体参数与返回值
+	Runtime     string   // PHP 运行时：RuntimeLibrary（默认）或 RuntimeEmbedded
+	Preload     bool     // 生成 opcache 预加载脚本（需要 RuntimeEmbedded）
+	OutputDir   string   // PHP 文件输出目录，为空时为源码目录下的 dist
+	LibDir      string   // 共享库目录，为空时为 OutputDir 下的 lib
+	Naming      Naming   // PHP 方法名、类名与命名空间的命名策略，零值保持原样
+}
+
+// Result 描述一次代码生成的结果
+type Result struct {
+	ServiceName string         // 实际使用的服务名
+	SourceFiles []string       // 参与解析的源文件
+	Files       []string       // 写入的文件（PHP 服务类、预加载脚本、Go 适配层、API 清单）
+	PHPFile     string         // PHP 服务类文件路径
+	Symbols     []string       // 共享库需要导出、由 PHP 服务类通过 FFI 调用的 C 符号
+	Wrapped     []string       // 被适配函数包装的原始 //export 函数（共享库仍然导出，但 PHP 不直接调用）
+	ABIHash     string         // PHP 通过 FFI 看到的 C 声明的哈希（与 API 清单中的 abi_hash 相同）
+	Exports     []ExportedFunc // 导出的函数（不含句柄类型的构造函数与方法）
+	Structs     []StructDef    // //gophp:struct 结构体
+	Handles     []*HandleDef   // //gophp:handle 句柄类型
+	Warnings    []string       // 被跳过的声明等非致命问题
+}
+
+// Generate 解析 Go 源码并生成 PHP 绑定与 Go 适配层，不向标准输出打印任何内容
+func Generate(options Options) (Result, error) {
+	return run(options, true)
+}
+
+// Inspect 与 Generate 使用相同的选项解析 Go 源码，但不写入任何文件，
+// 返回的 Result 中 Files 为空，其余字段与 Generate 一致
+func Inspect(options Options) (Result, error) {
+	return run(options, false)
+}
+
+// run 执行一次代码生成，write 为 false 时只解析不写入
+func run(options Options, write bool) (Result, error) {
+	if options.ResultMode == "" {
+		options.ResultMode = ResultModeArray
+	}
+	if options.ResultMode != ResultModeArray && options.ResultMode != ResultModeClass {
+		return Result{}, fmt.Errorf("unknown result mode %q (expected %s or %s)", options.ResultMode, ResultModeArray, ResultModeClass)
+	}
+	if options.Runtime == "" {
+		options.Runtime = RuntimeLibrary
+	}
+	if options.Runtime != RuntimeLibrary && options.Runtime != RuntimeEmbedded {
+		return Result{}, fmt.Errorf("unknown runtime %q (expected %s or %s)", options.Runtime, RuntimeLibrary, RuntimeEmbedded)
+	}
+	if options.Preload && options.Runtime != RuntimeEmbedded {
+		return Result{}, fmt.Errorf("preload requires the %s runtime", RuntimeEmbedded)
+	}
+
+	if err := options.Naming.validate(); err != nil {
+		return Result{}, err
+	}
+	names := newNamer(options.Naming)
+
+	if options.Source == "" {
+		return Result{}, fmt.Errorf("no source specified")
+	}
+	pkg, err := loadSource(options.Source, options.Tags)
+	if err != nil {
+		return Result{}, fmt.Errorf("load source: %w", err)
+	}
+
+	// 库基本名称默认为文件名或目录名
+	result := Result{ServiceName: pkg.Name, SourceFiles: pkg.Files}
+	if options.ServiceName != "" {
+		result.ServiceName = options.ServiceName
+	}
+
+	parsed, err := parsePackage(pkg.Files, options.JSON)
+	if err != nil {
+		return Result{}, fmt.Errorf("parse exports: %w", err)
+	}
+	if err := applyNaming(parsed, names); err != nil {
+		return Result{}, fmt.Errorf("naming: %w", err)
+	}
+	result.Exports = parsed.Exports
+	result.Structs = parsed.Structs
+	result.Handles = parsed.Handles
+	result.Warnings = parsed.Warnings
+
+	// PHP 文件默认输出到源码目录下的 dist 目录
+	distDir := options.OutputDir
+	if distDir == "" {
+		distDir = filepath.Join(pkg.Dir, "dist")
+	}
+	libDir := options.LibDir
+	if libDir == "" {
+		libDir = filepath.Join(distDir, "lib")
+	}
+
+	// 命名空间与类名先展开模板，再应用命名策略；父类是已有的类，保持原样
+	namespace, err := expandPHPName(options.Namespace, DefaultNamespace, result.ServiceName, true, names)
+	if err != nil {
+		return Result{}, fmt.Errorf("namespace: %w", err)
+	}
+	namespace = names.namespace(namespace)
+	className, err := expandPHPName(options.ClassName, DefaultClassName, result.ServiceName, false, names)
+	if err != nil {
+		return Result{}, fmt.Errorf("class name: %w", err)
+	}
+	className = names.class(className)
+	baseClass := ""
+	if options.BaseClass != "" || options.Runtime == RuntimeLibrary {
+		if baseClass, err = expandPHPName(options.BaseClass, DefaultBaseClass, result.ServiceName, true, names); err != nil {
+			return Result{}, fmt.Errorf("base class: %w", err)
+		}
+	}
+	phpFile := filepath.Join(distDir, className+".php")
+	if options.PHPFile != "" {
+		if phpFile, err = expandName(options.PHPFile, result.ServiceName, names); err != nil {
+			return Result{}, fmt.Errorf("PHP file: %w", err)
+		}
+		if !filepath.IsAbs(phpFile) {
+			phpFile = filepath.Join(distDir, phpFile)
+		}
+	}
+
+	opts := generateOptions{
+		ServiceName: result.ServiceName,
+		SourceDir:   pkg.Dir,
+		PackageName: pkg.PackageName,
+		Namespace:   namespace,
+		ClassName:   className,
+		BaseClass:   baseClass,
+		PHPFile:     phpFile,
+		OutputDir:   filepath.Dir(phpFile),
+		LibDir:      libDir,
+		ResultMode:  options.ResultMode,
+		JSON:        options.JSON,
+		Runtime:     options.Runtime,
+		Preload:     options.Preload,
+	}
+	if opts.Runtime == RuntimeLibrary && baseClass == DefaultBaseClass && filepath.Base(libDir) != "lib" {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("GoLibraryBase loads libraries from <base dir>/lib, but the lib dir is %s; use the %s runtime for custom layouts", libDir, RuntimeEmbedded))
+	}
+	result.PHPFile = phpFile
+	result.Symbols = exportedSymbols(parsed, opts.ServiceName)
+	result.Wrapped = wrappedExports(parsed)
+	manifest := buildManifest(parsed, opts, pkg.ImportPath, distDir)
+	result.ABIHash = manifest.ABIHash
+	if !write {
+		return result, nil
+	}
+
+	// 创建输出目录、清单目录与库目录
+	for _, dir := range []string{opts.OutputDir, distDir, libDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return result, fmt.Errorf("create directory: %w", err)
+		}
+	}
+
+	serviceFile, err := generateFFIBindings(parsed, opts)
+	if err != nil {
+		return result, fmt.Errorf("generate service class: %w", err)
+	}
+	result.Files = append(result.Files, serviceFile)
+
+	if opts.Preload {
+		preloadFile, err := generatePreloadScript(opts)
+		if err != nil {
+			return result, fmt.Errorf("generate preload script: %w", err)
+		}
+		result.Files = append(result.Files, preloadFile)
+	}
+
+	shimFile, err := generateGoShims(parsed, opts)
+	if err != nil {
+		return result, fmt.Errorf("generate Go shims: %w", err)
+	}
+	result.Files = append(result.Files, shimFile)
+	legacyShim, err := removeLegacyShim(opts)
+	if err != nil {
+		return result, fmt.Errorf("remove legacy Go shims: %w", err)
+	}
+	if legacyShim != "" {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("removed %s, which was generated under the old shim file name; %s replaces it", legacyShim, shimFile))
+	}
+
+	manifestFile, err := writeManifest(manifest, distDir)
+	if err != nil {
+		return result, fmt.Errorf("write manifest: %w", err)
+	}
+	result.Files = append(result.Files, manifestFile)
+
+	return result, nil
+}
+
+// toSnakeCase 转换为 snake_case，能识别缩写，例如 HTTPServer -> http_server
+func toSnakeCase(s string) string {
+	return defaultNamer.snake(s)
+}
+
+// toPascalCase 转换为 PascalCase，内置缩写保持全大写，例如 http_server -> HTTPServer
+func toPascalCase(s string) string {
+	return defaultNamer.pascal(s)
+}
+
+// lowerCamel 将 Go 导出标识符转换为 lowerCamelCase
+// 开头的缩写整体转为小写，例如 ID -> id、HTTPServer -> httpServer
+func lowerCamel(s string) string {
+	return defaultNamer.camel(s)
+}
+
+// generateFFIBindings 生成 service，返回写入的文件路径
+func generateFFIBindings(pkg *parsedPackage, opts generateOptions) (string, error) {
+	var sb strings.Builder
+	exports := pkg.funcs()
+
+	// 生成 PHP 类头部
+	sb.WriteString(fmt.Sprintf(`<?php
+/**
+ * FFI Bindings Service
+ * Auto-generated by Go-PHP FFI Code Generator
+ * 
+ * Provides PHP interface to Go shared library functions
+ */
+
+namespace %s;
+
+`, opts.Namespace))
+
+	if opts.Runtime == RuntimeEmbedded {
+		sb.WriteString(generateEmbeddedRuntime(pkg, opts))
+	} else {
+		sb.WriteString(fmt.Sprintf(`use %s;
+
+class %s extends %s {
+
+	/**
+     * 获取基础目录
+     * @return string
+     */
+    protected function getBaseDir(): string
+    {
+        return %s;
+    }
+`, opts.BaseClass, opts.ClassName, phpShortName(opts.BaseClass), phpBaseDirExpr(opts)))
+	}
+
+	// 为每个导出的函数生成包装方法
+	for _, exp := range exports {
+		sb.WriteString(fmt.Sprintf("    /**\n"))
+		if exp.Comment != "" {
+			sb.WriteString(fmt.Sprintf("     * %s\n", exp.Comment))
+		}
+		if h := handleOf(exp); h != nil {
+			sb.WriteString(fmt.Sprintf("     * @internal 由 %s 类调用\n", h.PHPName))
+		}
+		sb.WriteString(generatePHPMethodSignatureDoc(exp, opts))
+		sb.WriteString(fmt.Sprintf("     */\n"))
+		sb.WriteString(generatePHPMethod(exp, opts))
+		sb.WriteString("\n")
+	}
+
+	if hasStringParams(exports) {
+		sb.WriteString(generateStringHelpers())
+		sb.WriteString("\n")
+	}
+	if hasSliceParams(exports) {
+		sb.WriteString(generateSliceHelpers())
+		sb.WriteString("\n")
+	}
+	if hasCMemoryResults(exports) || len(pkg.Structs) > 0 {
+		sb.WriteString(generateCMemoryHelpers(opts))
+	}
+	if hasSliceResults(exports) {
+		sb.WriteString("\n")
+		sb.WriteString(generateSliceResultHelper(opts))
+	}
+	if hasJSONFuncs(exports) {
+		sb.WriteString("\n")
+		sb.WriteString(generateJSONHelper())
+	}
+	if len(pkg.Structs) > 0 {
+		sb.WriteString(generateStructHelpers(pkg.Structs, opts.ServiceName))
+	}
+	if hasErrorResults(exports) {
+		sb.WriteString("\n")
+		sb.WriteString(generateErrorHelper())
+	}
+
+	sb.WriteString(`}
+`)
+
+	// 以下辅助类可能由同一命名空间下的多个服务文件声明，只在尚未声明时定义
+	// Go error 对应的异常类
+	if hasErrorResults(exports) {
+		sb.WriteString("\n")
+		sb.WriteString(declareOnce("GoServiceException", generateExceptionClass()))
+	}
+
+	// 结构体对应的 DTO 类
+	for _, def := range pkg.Structs {
+		sb.WriteString("\n")
+		sb.WriteString(declareOnce(def.PHPName, generateDTOClass(def)))
+	}
+
+	// 句柄类型对应的 PHP 类
+	for _, h := range pkg.Handles {
+		sb.WriteString("\n")
+		sb.WriteString(declareOnce(h.PHPName, generateHandleClass(h, opts)))
+	}
+
+	// 多返回值的结果类
+	for _, exp := range exports {
+		if hasMultiResults(exp) && resultMode(exp, opts.ResultMode) == ResultModeClass {
+			sb.WriteString("\n")
+			sb.WriteString(declareOnce(resultClassName(exp), generateResultClass(exp)))
+		}
+	}
+
+	// 使用动态生成的文件名，输出到指定目录
+	return opts.PHPFile, os.WriteFile(opts.PHPFile, []byte(sb.String()), 0644)
+}
+
+// declareOnce 将 PHP 类声明包裹在 class_exists 检查中，
+// 避免同一命名空间下的多个服务文件重复声明同名类
+func declareOnce(className, class string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("if (!class_exists(%s::class, false)) {\n", className))
+	for _, line := range strings.SplitAfter(class, "\n") {
+		if strings.TrimSpace(line) != "" {
+			sb.WriteString("    ")
+		}
+		sb.WriteString(line)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// generatePHPMethodSignatureDoc 为方法生成 PHPDoc
+func generatePHPMethodSignatureDoc(exp ExportedFunc, opts generateOptions) string {
+	var sb strings.Builder
+
+	if exp.Receiver != nil {
+		sb.WriteString("     * @param int $handle\n")
+	}
+	for _, param := range exp.Params {
+		phpType := paramPHPDoc(exp, param)
+		sb.WriteString(fmt.Sprintf("     * @param %s $%s\n", phpType, param.Name))
+	}
+
+	sb.WriteString(fmt.Sprintf("     * @return %s\n", returnPHPDoc(exp, opts.ResultMode)))
+	if canThrow(exp) {
+		sb.WriteString("     * @throws GoServiceException\n")
+	}
+	if encodesJSON(exp) {
+		sb.WriteString("     * @throws \\JsonException\n")
+	}
+
+	return sb.String()
+}
+
+// returnPHPDoc 返回 PHP 方法在 PHPDoc 中的返回类型
+func returnPHPDoc(exp ExportedFunc, defaultMode string) string {
+	if hasMultiResults(exp) {
+		_, phpType := multiResultPHPType(exp, resultMode(exp, defaultMode))
+		return phpType
+	}
+	if values := valueResults(exp); len(values) == 1 {
+		return resultPHPDoc(exp, values[0])
+	}
+	return "void"
+}
+
+// generatePHPMethod 生成 PHP 方法包装器
+func generatePHPMethod(exp ExportedFunc, opts generateOptions) string {
+	var sb strings.Builder
+
+	// 方法签名
+	sb.WriteString(fmt.Sprintf("    public function %s(%s)", serviceMethodName(exp), strings.Join(phpMethodParams(exp), ", ")))
+
+	// 返回类型
+	mode := resultMode(exp, opts.ResultMode)
+	if returnType := phpMethodReturnType(exp, opts); returnType != "" {
+		sb.WriteString(fmt.Sprintf(": %s", returnType))
+	}
+
+	sb.WriteString(" {\n")
+
+	// 方法体 - 调用 FFI 函数
+	callParams := []string{}
+	if exp.Receiver != nil {
+		callParams = append(callParams, "$handle")
+	}
+	for _, param := range exp.Params {
+		callParams = append(callParams, phpArgValue(exp, param))
+	}
+	if needsBuffers(exp) {
+		sb.WriteString(fmt.Sprintf("        $%s = [];\n", buffersVar))
+	}
+	call := fmt.Sprintf("$this->ffi->%s(%s)", ffiSymbol(exp, opts.ServiceName), strings.Join(callParams, ", "))
+
+	layout := layoutOf(exp)
+	switch {
+	case layout.Total == 0:
+		sb.WriteString(fmt.Sprintf("        %s;\n", call))
+	case layout.Total == 1 && layout.ErrField == 0:
+		// 适配函数只返回错误信息
+		sb.WriteString(fmt.Sprintf("        $this->throwIfGoError('%s', %s);\n", exp.Name, call))
+	case layout.Total == 1:
+		sb.WriteString(fmt.Sprintf("        return %s;\n", phpResultValues(exp, layout, func(int) string { return call })[0]))
+	default:
+		// cgo 将多个返回值包装为 struct <Name>_return { r0; r1; ... }
+		sb.WriteString(fmt.Sprintf("        $result = %s;\n", call))
+		field := func(i int) string { return "$result->" + cgoResultField(i) }
+		if layout.ErrField >= 0 {
+			sb.WriteString(fmt.Sprintf("        $this->throwIfGoError('%s', %s);\n", exp.Name, field(layout.ErrField)))
+		}
+		exprs := phpResultValues(exp, layout, field)
+		switch {
+		case hasMultiResults(exp):
+			sb.WriteString(fmt.Sprintf("        return %s;\n", generateResultUnpack(exp, mode, exprs)))
+		case len(exprs) == 1:
+			sb.WriteString(fmt.Sprintf("        return %s;\n", exprs[0]))
+		}
+	}
+
+	sb.WriteString("    }\n")
+
+	return sb.String()
+}
+
+// phpMethodParams 返回 PHP 方法的参数列表（句柄方法以 int $handle 开头）
+func phpMethodParams(exp ExportedFunc) []string {
+	paramStrs := []string{}
+	if exp.Receiver != nil {
+		paramStrs = append(paramStrs, "int $handle")
+	}
+	for _, param := range exp.Params {
+		phpType := paramPHPHint(exp, param)
+		if phpType != "" {
+			paramStrs = append(paramStrs, fmt.Sprintf("%s $%s", phpType, param.Name))
+		} else {
+			paramStrs = append(paramStrs, fmt.Sprintf("$%s", param.Name))
+		}
+	}
+	return paramStrs
+}
+
+// phpMethodReturnType 返回 PHP 方法的返回类型提示（无法确定时为空）
+func phpMethodReturnType(exp ExportedFunc, opts generateOptions) string {
+	values := valueResults(exp)
+	if hasMultiResults(exp) {
+		returnType, _ := multiResultPHPType(exp, resultMode(exp, opts.ResultMode))
+		return returnType
+	}
+	if len(values) == 1 {
+		return resultPHPHint(exp, values[0])
+	}
+	return ""
+}
+
+// cTypeToPHPType 将 C/Go 类型转换为 PHP 类型用于文档
+func cTypeToPHPType(cType string) string {
+	cType = strings.TrimSpace(cType)
+
+	// 字符串类型（char* 和 string 类型）
+	if strings.Contains(cType, "*C.char") || cType == "*C.char" || cType == "char*" {
+		return "string"
+	}
+	if cType == "string" || cType == "GoString" {
+		return "string"
+	}
+
+	if strings.Contains(cType, "GoMap") {
+		return "array"
+	}
+	if strings.Contains(cType, "GoSlice") {
+		return "array"
+	}
+	if strings.Contains(cType, "[]") {
+		return "array"
+	}
+
+	// 整数类型 - C
+	if cType == "int" || cType == "int8" || cType == "int16" || cType == "int32" || cType == "int64" {
+		return "int"
+	}
+	if cType == "uint" || cType == "uint8" || cType == "uint16" || cType == "uint32" || cType == "uint64" {
+		return "int"
+	}
+	if cType == "char" || cType == "short" || cType == "long" || cType == "long long" {
+		return "int"
+	}
+	if cType == "unsigned char" || cType == "unsigned short" || cType == "unsigned int" || cType == "unsigned long" {
+		return "int"
+	}
+	if cType == "size_t" || cType == "ssize_t" {
+		return "int"
+	}
+
+	// 整数类型 - Go/CGO
+	if cType == "GoInt" || cType == "GoInt8" || cType == "GoInt16" || cType == "GoInt32" || cType == "GoInt64" {
+		return "int"
+	}
+	if cType == "GoUint" || cType == "GoUint8" || cType == "GoUint16" || cType == "GoUint32" || cType == "GoUint64" {
+		return "int"
+	}
+	if cType == "C.int" || cType == "C.long" || cType == "C.short" || cType == "C.char" {
+		return "int"
+	}
+
+	// 浮点数类型 - C
+	if cType == "float" || cType == "float32" || cType == "float64" || cType == "double" {
+		return "float"
+	}
+
+	// 浮点数类型 - Go/CGO
+	if cType == "GoFloat32" || cType == "GoFloat64" {
+		return "float"
+	}
+	if cType == "C.float" || cType == "C.double" {
+		return "float"
+	}
+
+	// 布尔类型
+	if cType == "bool" || cType == "GoBool" || cType == "_Bool" {
+		return "bool"
+	}
+
+	// void 类型
+	if cType == "void" || cType == "" {
+		return "void"
+	}
+
+	return "mixed"
+}
+
+// cTypeToPHPDoc 将 C/Go 类型转换为 PHP 类型提示
+func cTypeToPHPDoc(cType string) string {
+	cType = strings.TrimSpace(cType)
+
+	// 字符串类型（char* 和 string 类型）
+	if strings.Contains(cType, "*C.char") || cType == "*C.char" || cType == "char*" {
+		return "string"
+	}
+	if cType == "string" || cType == "GoString" {
+		return "string"
+	}
+
+	// 数组/指针类型（排除 char*）
+	// Go Map、Slice 和 Array 类型
+	if strings.Contains(cType, "map[") || strings.HasPrefix(cType, "map[") {
+		return "array"
+	}
+	if strings.Contains(cType, "GoMap") {
+		return "array"
+	}
+	if strings.Contains(cType, "GoSlice") {
+		return "array"
+	}
+	if strings.Contains(cType, "[]") {
+		return "array"
+	}
+	if strings.HasPrefix(cType, "*") && !strings.Contains(cType, "char") {
+		return "array"
+	}
+
+	// 整数类型 - C
+	if cType == "int" || cType == "int8" || cType == "int16" || cType == "int32" || cType == "int64" {
+		return "int"
+	}
+	if cType == "uint" || cType == "uint8" || cType == "uint16" || cType == "uint32" || cType == "uint64" {
+		return "int"
+	}
+	if cType == "char" || cType == "short" || cType == "long" || cType == "long long" {
+		return "int"
+	}
+	if cType == "unsigned char" || cType == "unsigned short" || cType == "unsigned int" || cType == "unsigned long" {
+		return "int"
+	}
+	if cType == "size_t" || cType == "ssize_t" {
+		return "int"
+	}
+
+	// 整数类型 - Go/CGO
+	if cType == "GoInt" || cType == "GoInt8" || cType == "GoInt16" || cType == "GoInt32" || cType == "GoInt64" {
+		return "int"
+	}
+	if cType == "GoUint" || cType == "GoUint8" || cType == "GoUint16" || cType == "GoUint32" || cType == "GoUint64" {
+		return "int"
+	}
+	if cType == "C.int" || cType == "C.long" || cType == "C.short" || cType == "C.char" {
+		return "int"
+	}
+
+	// 浮点数类型 - C
+	if cType == "float" || cType == "float32" || cType == "float64" || cType == "double" {
+		return "float"
+	}
+
+	// 浮点数类型 - Go/CGO
+	if cType == "GoFloat32" || cType == "GoFloat64" {
+		return "float"
+	}
+	if cType == "C.float" || cType == "C.double" {
+		return "float"
+	}
+
+	// 布尔类型
+	if cType == "bool" || cType == "GoBool" || cType == "_Bool" {
+		return "bool"
+	}
+
+	// void 类型（不使用类型提示）
+	if cType == "void" || cType == "" {
+		return ""
+	}
+
+	// 未知类型：返回空（不使用类型提示）
+	return ""
+}
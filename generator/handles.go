@@ -0,0 +1,313 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// HandleDef 表示一个通过 //gophp:handle 指令以 cgo.Handle 形式导出的 Go 类型
+// PHP 端为其生成同名类：构造函数创建句柄，方法调用适配函数，析构时释放句柄
+type HandleDef struct {
+	Name        string         // Go 类型名
+	PHPName     string         // PHP 类名（按类名策略由 Name 转换）
+	Comment     string         // 单行化的文档注释
+	Pos         token.Position // 类型声明在源码中的位置
+	Named       *types.Named   // 类型检查得到的命名类型
+	Ctor        string         // 构造函数名（默认为 New<Name>）
+	Constructor ExportedFunc   // 构造函数，返回 *<Name> 或 (*<Name>, error)
+	Methods     []ExportedFunc // 导出的方法
+	Close       ExportedFunc   // 释放句柄的函数（存在 Close 方法时会先调用它）
+}
+
+// funcs 返回句柄类型对应的全部导出函数（构造函数、方法、释放函数）
+func (h *HandleDef) funcs() []ExportedFunc {
+	funcs := []ExportedFunc{h.Constructor}
+	funcs = append(funcs, h.Methods...)
+	return append(funcs, h.Close)
+}
+
+// collectHandles 提取带有 //gophp:handle [Constructor] 指令的类型
+func collectHandles(fset *token.FileSet, file *ast.File, info *types.Info) ([]*HandleDef, []string) {
+	var handles []*HandleDef
+	var warnings []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			ctor, ok := parseDirectives(doc)["handle"]
+			if !ok {
+				continue
+			}
+
+			pos := fset.Position(ts.Pos())
+			obj, _ := info.Defs[ts.Name].(*types.TypeName)
+			if obj == nil {
+				warnings = append(warnings, fmt.Sprintf("%s: cannot resolve type %s", pos, ts.Name.Name))
+				continue
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("%s: cannot resolve type %s", pos, ts.Name.Name))
+				continue
+			}
+			if ctor == "" {
+				ctor = "New" + ts.Name.Name
+			}
+
+			comment, _ := docText(doc)
+			handles = append(handles, &HandleDef{
+				Name:    ts.Name.Name,
+				Comment: comment,
+				Pos:     pos,
+				Named:   named,
+				Ctor:    ctor,
+			})
+		}
+	}
+	return handles, warnings
+}
+
+// attachHandleFuncs 为句柄类型关联构造函数与方法，缺少合法构造函数的类型会被丢弃
+func attachHandleFuncs(fset *token.FileSet, files []*ast.File, info *types.Info, handles []*HandleDef) ([]*HandleDef, []string) {
+	byName := make(map[string]*HandleDef)
+	for _, h := range handles {
+		byName[h.Name] = h
+	}
+	found := make(map[*HandleDef]bool)
+	closers := make(map[*HandleDef]*ExportedFunc)
+	var warnings []string
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if fn.Recv == nil {
+				for _, h := range handles {
+					if fn.Name.Name == h.Ctor {
+						h.Constructor = newExportedFunc(fset, fn, info, h.Name+"_New")
+						found[h] = true
+					}
+				}
+				continue
+			}
+
+			h := byName[receiverTypeName(fn.Recv)]
+			if h == nil || !fn.Name.IsExported() {
+				continue
+			}
+			method := newExportedFunc(fset, fn, info, h.Name+"_"+fn.Name.Name)
+			method.Receiver = h
+			if fn.Name.Name == "Close" {
+				if len(method.Params) > 0 || len(valueResults(method)) > 0 {
+					warnings = append(warnings, fmt.Sprintf("%s: ignoring %s.Close: expected func() or func() error", method.Pos, h.Name))
+					continue
+				}
+				closers[h] = &method
+				continue
+			}
+			h.Methods = append(h.Methods, method)
+		}
+	}
+
+	var valid []*HandleDef
+	for _, h := range handles {
+		if !found[h] {
+			warnings = append(warnings, fmt.Sprintf("%s: skipping %s: constructor %s not found", h.Pos, h.Name, h.Ctor))
+			continue
+		}
+		if !bindConstructor(h) {
+			warnings = append(warnings, fmt.Sprintf("%s: skipping %s: %s must return *%s or (*%s, error)", h.Constructor.Pos, h.Name, h.Ctor, h.Name, h.Name))
+			continue
+		}
+
+		h.Close = ExportedFunc{
+			Name:       h.Name + "_Close",
+			Comment:    fmt.Sprintf("释放 %s 句柄", h.Name),
+			ReturnType: "void",
+			Pos:        h.Pos,
+			Directives: map[string]string{},
+		}
+		if closer := closers[h]; closer != nil {
+			h.Close = *closer
+		}
+		h.Close.Receiver = h
+		h.Close.Release = true
+		valid = append(valid, h)
+	}
+	return valid, warnings
+}
+
+// bindConstructor 检查构造函数的返回值并将其标记为句柄
+func bindConstructor(h *HandleDef) bool {
+	values := valueResults(h.Constructor)
+	if len(values) != 1 || values[0].GoType == nil {
+		return false
+	}
+	ptr, ok := values[0].GoType.(*types.Pointer)
+	if !ok || !types.Identical(ptr.Elem(), h.Named) {
+		return false
+	}
+	h.Constructor.Results[0].Handle = h
+	return true
+}
+
+// receiverTypeName 返回方法接收者的类型名（去掉指针与类型参数）
+func receiverTypeName(recv *ast.FieldList) string {
+	if recv == nil || len(recv.List) == 0 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// handleOf 返回函数所属的句柄类型（构造函数、方法或释放函数），普通函数返回 nil
+func handleOf(exp ExportedFunc) *HandleDef {
+	if exp.Receiver != nil {
+		return exp.Receiver
+	}
+	for _, r := range exp.Results {
+		if r.Handle != nil {
+			return r.Handle
+		}
+	}
+	return nil
+}
+
+// generateHandleClass 生成句柄类型对应的 PHP 类
+func generateHandleClass(h *HandleDef, opts generateOptions) string {
+	var sb strings.Builder
+	serviceClass := opts.ClassName
+
+	sb.WriteString("/**\n")
+	if h.Comment != "" {
+		sb.WriteString(fmt.Sprintf(" * %s\n", h.Comment))
+	} else {
+		sb.WriteString(fmt.Sprintf(" * Handle for Go type %s\n", h.Name))
+	}
+	sb.WriteString(" */\n")
+	sb.WriteString(fmt.Sprintf("final class %s {\n", h.PHPName))
+	sb.WriteString(fmt.Sprintf("    /** @var %s */\n", serviceClass))
+	sb.WriteString(fmt.Sprintf("    private %s $service;\n\n", serviceClass))
+	sb.WriteString("    /** @var int cgo.Handle，释放后为 0 */\n")
+	sb.WriteString("    private int $handle = 0;\n\n")
+
+	// 构造函数
+	ctor := h.Constructor
+	params := phpMethodParams(ctor)
+	sb.WriteString("    /**\n")
+	if ctor.Comment != "" {
+		sb.WriteString(fmt.Sprintf("     * %s\n", ctor.Comment))
+	}
+	sb.WriteString(fmt.Sprintf("     * @param %s $service\n", serviceClass))
+	for _, p := range ctor.Params {
+		sb.WriteString(fmt.Sprintf("     * @param %s $%s\n", paramPHPDoc(ctor, p), p.Name))
+	}
+	if canThrow(ctor) {
+		sb.WriteString("     * @throws GoServiceException\n")
+	}
+	if encodesJSON(ctor) {
+		sb.WriteString("     * @throws \\JsonException\n")
+	}
+	sb.WriteString("     */\n")
+	sb.WriteString(fmt.Sprintf("    public function __construct(%s) {\n", strings.Join(append([]string{serviceClass + " $service"}, params...), ", ")))
+	sb.WriteString("        $this->service = $service;\n")
+	sb.WriteString(fmt.Sprintf("        $this->handle = $service->%s(%s);\n", ctor.Name, strings.Join(phpArgNames(ctor.Params), ", ")))
+	sb.WriteString("    }\n")
+
+	// 方法：委托给服务类中的句柄方法
+	for _, m := range h.Methods {
+		local := m
+		local.Receiver = nil
+		sb.WriteString("\n    /**\n")
+		if m.Comment != "" {
+			sb.WriteString(fmt.Sprintf("     * %s\n", m.Comment))
+		}
+		sb.WriteString(generatePHPMethodSignatureDoc(local, opts))
+		sb.WriteString("     */\n")
+
+		params, returnType := phpMethodParams(local), phpMethodReturnType(local, opts)
+		sb.WriteString(fmt.Sprintf("    public function %s(%s)", m.PHPName, strings.Join(params, ", ")))
+		if returnType != "" {
+			sb.WriteString(": " + returnType)
+		}
+		sb.WriteString(" {\n")
+		args := append([]string{"$this->handle()"}, phpArgNames(m.Params)...)
+		call := fmt.Sprintf("$this->service->%s(%s)", m.Name, strings.Join(args, ", "))
+		if len(valueResults(m)) == 0 {
+			sb.WriteString(fmt.Sprintf("        %s;\n", call))
+		} else {
+			sb.WriteString(fmt.Sprintf("        return %s;\n", call))
+		}
+		sb.WriteString("    }\n")
+	}
+
+	// 释放
+	sb.WriteString("\n    /**\n")
+	sb.WriteString("     * 释放 Go 对象句柄，可重复调用\n")
+	sb.WriteString("     * @return void\n")
+	if canThrow(h.Close) {
+		sb.WriteString("     * @throws GoServiceException\n")
+	}
+	sb.WriteString("     */\n")
+	sb.WriteString(fmt.Sprintf("    public function %s(): void {\n", h.Close.PHPName))
+	sb.WriteString("        if ($this->handle === 0) {\n")
+	sb.WriteString("            return;\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("        $handle = $this->handle;\n")
+	sb.WriteString("        $this->handle = 0;\n")
+	sb.WriteString(fmt.Sprintf("        $this->service->%s($handle);\n", h.Close.Name))
+	sb.WriteString("    }\n\n")
+
+	sb.WriteString("    public function __destruct() {\n")
+	if canThrow(h.Close) {
+		// 析构函数中不抛出异常
+		sb.WriteString("        try {\n")
+		sb.WriteString(fmt.Sprintf("            $this->%s();\n", h.Close.PHPName))
+		sb.WriteString("        } catch (GoServiceException $e) {\n")
+		sb.WriteString("        }\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("        $this->%s();\n", h.Close.PHPName))
+	}
+	sb.WriteString("    }\n\n")
+
+	sb.WriteString("    /**\n")
+	sb.WriteString("     * 返回有效的句柄，已释放时抛出异常\n")
+	sb.WriteString("     * @return int\n")
+	sb.WriteString("     */\n")
+	sb.WriteString("    private function handle(): int {\n")
+	sb.WriteString("        if ($this->handle === 0) {\n")
+	sb.WriteString(fmt.Sprintf("            throw new \\LogicException('%s has been closed');\n", h.PHPName))
+	sb.WriteString("        }\n")
+	sb.WriteString("        return $this->handle;\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// phpArgNames 返回 PHP 参数变量列表
+func phpArgNames(params []Param) []string {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, "$"+p.Name)
+	}
+	return names
+}
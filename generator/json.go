@@ -0,0 +1,245 @@
+package generator
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// isComplexType 判断类型是否无法直接通过 cgo 传递（map、结构体及其切片/指针）
+// 这类参数与返回值只能通过 JSON 桥接
+func isComplexType(p Param) bool {
+	if p.GoType == nil {
+		return strings.HasPrefix(p.Type, "map[")
+	}
+	return isComplexGoType(p.GoType)
+}
+
+// isComplexGoType 判断 Go 类型是否需要 JSON 桥接
+func isComplexGoType(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Map, *types.Struct:
+		return true
+	case *types.Pointer:
+		_, ok := u.Elem().Underlying().(*types.Struct)
+		return ok
+	case *types.Slice:
+		if isGoBytes(Param{GoType: t}) || isGoSlice(Param{GoType: t}) {
+			return false
+		}
+		return isComplexGoType(u.Elem()) || isSliceType(u.Elem())
+	case *types.Array:
+		return true
+	}
+	return false
+}
+
+// isSliceType 判断类型是否为切片（用于识别 [][]int 等嵌套切片）
+func isSliceType(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Slice)
+	return ok
+}
+
+// hasComplexSignature 判断函数签名中是否包含需要 JSON 桥接的类型
+func hasComplexSignature(exp ExportedFunc) bool {
+	for _, p := range exp.Params {
+		if isComplexType(p) {
+			return true
+		}
+	}
+	for _, r := range valueResults(exp) {
+		if isComplexType(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSONValue 判断参数或返回值是否以 JSON 形式传递
+func isJSONValue(exp ExportedFunc, p Param) bool {
+	return exp.JSON && isComplexType(p)
+}
+
+// isObjectType 判断 JSON 值在 PHP 端为空数组时是否应编码为 {}（map 与结构体）
+func isObjectType(p Param) bool {
+	if p.GoType == nil {
+		return true
+	}
+	t := p.GoType.Underlying()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem().Underlying()
+	}
+	switch t.(type) {
+	case *types.Map, *types.Struct:
+		return true
+	}
+	return false
+}
+
+// canThrow 判断 PHP 方法是否可能抛出 GoServiceException
+// JSON 桥接函数在编解码失败时也会返回错误
+func canThrow(exp ExportedFunc) bool {
+	return returnsError(exp) || exp.JSON
+}
+
+// encodesJSON 判断 PHP 方法是否在 PHP 端编码 JSON 参数（json_encode 失败时抛出 \JsonException）
+func encodesJSON(exp ExportedFunc) bool {
+	for _, p := range exp.Params {
+		if isJSONValue(exp, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonBridgeName 返回 JSON 桥接函数的 Go 函数名
+func jsonBridgeName(exp ExportedFunc) string {
+	return "gophpJSON" + exp.Name
+}
+
+// jsonBridge 返回 JSON 桥接函数对应的 ExportedFunc
+// 复杂参数与返回值被替换为 string，并追加 error 返回值，以便复用通用的适配函数生成逻辑
+func jsonBridge(exp ExportedFunc) ExportedFunc {
+	stringType := types.Typ[types.String]
+	bridged := exp
+	bridged.GoName = jsonBridgeName(exp)
+	bridged.JSON = false
+	bridged.Params = nil
+	bridged.Results = nil
+
+	for _, p := range exp.Params {
+		if isComplexType(p) {
+			p = Param{Name: p.Name, Type: "string", GoType: stringType}
+		}
+		bridged.Params = append(bridged.Params, p)
+	}
+	for _, r := range valueResults(exp) {
+		if isComplexType(r) {
+			r = Param{Name: r.Name, Type: "string", GoType: stringType}
+		}
+		bridged.Results = append(bridged.Results, r)
+	}
+	bridged.Results = append(bridged.Results, Param{
+		Name:   "err",
+		Type:   "error",
+		GoType: types.Universe.Lookup("error").Type(),
+	})
+	return bridged
+}
+
+// generateJSONBridge 生成 JSON 桥接函数：解码 JSON 参数、调用原函数、编码 JSON 返回值
+func generateJSONBridge(exp ExportedFunc, imports map[string]bool) string {
+	imports["encoding/json"] = true
+	imports["fmt"] = true
+
+	var sb strings.Builder
+	name := jsonBridgeName(exp)
+	values := valueResults(exp)
+
+	// 参数统一命名为 pN，避免与内部变量冲突
+	var params []string
+	for i, p := range exp.Params {
+		if isComplexType(p) {
+			params = append(params, fmt.Sprintf("p%d string", i))
+		} else {
+			params = append(params, fmt.Sprintf("p%d %s", i, goTypeExpr(p, imports)))
+		}
+	}
+	var results []string
+	for i, r := range values {
+		if isComplexType(r) {
+			results = append(results, fmt.Sprintf("o%d string", i))
+		} else {
+			results = append(results, fmt.Sprintf("o%d %s", i, goTypeExpr(r, imports)))
+		}
+	}
+	results = append(results, "err error")
+
+	sb.WriteString(fmt.Sprintf("// %s 以 JSON 形式桥接 %s 的复杂参数与返回值\n", name, exp.GoName))
+	sb.WriteString(fmt.Sprintf("func %s(%s) (%s) {\n", name, strings.Join(params, ", "), strings.Join(results, ", ")))
+
+	// 解码 JSON 参数
+	var args []string
+	for i, p := range exp.Params {
+		if !isComplexType(p) {
+			args = append(args, fmt.Sprintf("p%d", i))
+			continue
+		}
+		arg := fmt.Sprintf("a%d", i)
+		sb.WriteString(fmt.Sprintf("\tvar %s %s\n", arg, goTypeExpr(p, imports)))
+		sb.WriteString(fmt.Sprintf("\tif err = json.Unmarshal([]byte(p%d), &%s); err != nil {\n", i, arg))
+		sb.WriteString(fmt.Sprintf("\t\terr = fmt.Errorf(\"decode %s: %%w\", err)\n", p.Name))
+		sb.WriteString("\t\treturn\n")
+		sb.WriteString("\t}\n")
+		args = append(args, arg)
+	}
+
+	// 调用原函数，复杂返回值先保存到临时变量
+	var lhs []string
+	for i, r := range values {
+		if isComplexType(r) {
+			v := fmt.Sprintf("v%d", i)
+			sb.WriteString(fmt.Sprintf("\tvar %s %s\n", v, goTypeExpr(r, imports)))
+			lhs = append(lhs, v)
+		} else {
+			lhs = append(lhs, fmt.Sprintf("o%d", i))
+		}
+	}
+	if returnsError(exp) {
+		lhs = append(lhs, "err")
+	}
+	call := fmt.Sprintf("%s(%s)", exp.GoName, strings.Join(args, ", "))
+	if len(lhs) == 0 {
+		sb.WriteString(fmt.Sprintf("\t%s\n", call))
+	} else {
+		sb.WriteString(fmt.Sprintf("\t%s = %s\n", strings.Join(lhs, ", "), call))
+	}
+	if returnsError(exp) {
+		sb.WriteString("\tif err != nil {\n\t\treturn\n\t}\n")
+	}
+
+	// 编码复杂返回值
+	for i, r := range values {
+		if !isComplexType(r) {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\tvar b%d []byte\n", i))
+		sb.WriteString(fmt.Sprintf("\tif b%d, err = json.Marshal(v%d); err != nil {\n", i, i))
+		sb.WriteString(fmt.Sprintf("\t\terr = fmt.Errorf(\"encode %s: %%w\", err)\n", r.Name))
+		sb.WriteString("\t\treturn\n")
+		sb.WriteString("\t}\n")
+		sb.WriteString(fmt.Sprintf("\to%d = string(b%d)\n", i, i))
+	}
+	sb.WriteString("\treturn\n")
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// generateJSONHelper 生成将 PHP 值编码为 JSON 的私有方法
+func generateJSONHelper() string {
+	return `    /**
+     * 将 PHP 值编码为 JSON，空数组在对应 Go map/结构体时编码为 {}
+     * @param mixed $value
+     * @param bool $object
+     * @return string
+     * @throws \JsonException
+     */
+    private function toJSON($value, bool $object): string {
+        if ($object && $value === []) {
+            return '{}';
+        }
+        return json_encode($value, JSON_THROW_ON_ERROR | JSON_UNESCAPED_UNICODE | JSON_PRESERVE_ZERO_FRACTION);
+    }
+`
+}
+
+// hasJSONFuncs 判断是否存在 JSON 桥接函数
+func hasJSONFuncs(exports []ExportedFunc) bool {
+	for _, exp := range exports {
+		if exp.JSON {
+			return true
+		}
+	}
+	return false
+}
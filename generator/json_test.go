@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONMethodsDocumentJsonException(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "svc.go")
+	src := `package main
+
+import "C"
+
+// SaveTags 保存标签
+//
+//gophp:json
+func SaveTags(tags map[string]int) error { return nil }
+
+// LoadTags 读取标签
+//
+//gophp:json
+func LoadTags(id int) map[string]int { return nil }
+
+func main() {}
+`
+	if err := os.WriteFile(source, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Generate(Options{Source: source, ServiceName: "Svc", OutputDir: filepath.Join(dir, "dist")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	php, err := os.ReadFile(result.PHPFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只有编码 JSON 参数的方法会在 PHP 端抛出 \JsonException
+	docs := make(map[string]string)
+	for _, chunk := range strings.Split(string(php), "/**")[1:] {
+		doc, rest, _ := strings.Cut(chunk, "*/")
+		if name, _, ok := strings.Cut(strings.TrimSpace(rest), "("); ok {
+			docs[name] = doc
+		}
+	}
+	for method, want := range map[string]bool{
+		"public function SaveTags": true,
+		"public function LoadTags": false,
+		"private function toJSON":  true,
+	} {
+		doc, ok := docs[method]
+		if !ok {
+			t.Fatalf("method %s not found in:\n%s", method, php)
+		}
+		if got := strings.Contains(doc, `@throws \JsonException`); got != want {
+			t.Errorf("%s documents \\JsonException = %v, want %v", method, got, want)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+)
+
+// 诊断的严重程度
+const (
+	SeverityError   = "error"   // 构建失败或 PHP 端无法使用
+	SeverityWarning = "warning" // 生成器会跳过相关声明
+)
+
+// Lint 规则 ID
+const (
+	RulePackageMain       = "package-main"       // c-shared 构建要求 main 包
+	RuleMissingMain       = "missing-main"       // 缺少 func main
+	RuleMissingImportC    = "missing-import-c"   // 含 //export 的文件没有 import "C"
+	RuleDetachedExport    = "detached-export"    // //export 与 func 之间有空行，cgo 会忽略该指令
+	RuleDetachedDirective = "detached-directive" // //gophp: 指令与声明之间有空行，生成器会忽略该指令
+	RuleExportName        = "export-name"        // //export 名称与函数名不一致
+	RuleMapType           = "map-type"           // //export 签名中的 map
+	RuleInterfaceType     = "interface-type"     // //export 签名中的 interface
+	RuleChanType          = "chan-type"          // //export 签名中的 channel
+	RuleFuncType          = "func-type"          // //export 签名中的函数值
+	RuleGoPointerResult   = "go-pointer-result"  // //export 返回 Go 指针
+	RuleSliceParam        = "slice-param"        // //export 参数为 PHP 数组无法构造的切片
+	RuleStructType        = "struct-type"        // //export 签名中的 Go 结构体
+	RuleStructGoPointer   = "struct-go-pointer"  // 结构体字段含有 Go 指针
+)
+
+// Diagnostic 描述 Lint 发现的一个问题
+type Diagnostic struct {
+	Pos      token.Position // 问题所在位置
+	Rule     string         // 规则 ID（Rule* 常量）
+	Severity string         // SeverityError 或 SeverityWarning
+	Message  string         // 问题描述
+	Fix      string         // 修复建议
+
+	edits []textEdit // Fix 可以自动应用的修改
+}
+
+// textEdit 将源文件中 [start, end) 的字节替换为 text
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// LintResult 描述一次 Lint 的结果
+type LintResult struct {
+	Package     string       // 包的导入路径（单文件模式为 command-line-arguments，与 go vet 一致）
+	Files       []string     // 检查的源文件
+	Diagnostics []Diagnostic // 按文件与位置排序的诊断
+}
+
+// HasErrors 判断是否存在 SeverityError 级别的诊断
+func (r LintResult) HasErrors() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint 检查 Go 源码中在 PHP FFI 下无法使用或不安全的导出签名，只使用 Options 中的 Source 与 Tags
+// 生成器输出的适配层文件与带有 "Code generated" 标记的文件不参与检查
+func Lint(options Options) (LintResult, error) {
+	l, pkg, err := lintSource(options)
+	if err != nil {
+		return LintResult{}, err
+	}
+	return LintResult{Package: pkg.ImportPath, Files: pkg.Files, Diagnostics: l.diags}, nil
+}
+
+// lintSource 解析并检查源码，返回带有自动修复信息的 linter
+func lintSource(options Options) (*linter, *sourcePackage, error) {
+	if options.Source == "" {
+		return nil, nil, fmt.Errorf("no source specified")
+	}
+	pkg, err := loadSource(options.Source, options.Tags)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load source: %w", err)
+	}
+
+	l := &linter{fset: token.NewFileSet(), src: make(map[string][]byte)}
+	for _, filename := range pkg.Files {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		file, err := parser.ParseFile(l.fset, filename, src, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		l.src[filename] = src
+		l.files = append(l.files, file)
+	}
+	l.info = typeCheck(l.fset, l.files)
+
+	l.checkPackage()
+	for _, file := range l.files {
+		if ast.IsGenerated(file) {
+			continue
+		}
+		l.checkExports(file)
+		l.checkStructs(file)
+	}
+
+	l.sortDiagnostics()
+	return l, pkg, nil
+}
+
+// linter 收集单个包的诊断
+type linter struct {
+	fset  *token.FileSet
+	info  *types.Info
+	files []*ast.File
+	src   map[string][]byte // 文件名到源码内容
+	diags []Diagnostic
+}
+
+// report 记录一条诊断，返回值可用于附加自动修复
+func (l *linter) report(pos token.Pos, severity, rule, fix, format string, args ...any) *Diagnostic {
+	l.diags = append(l.diags, Diagnostic{
+		Pos:      l.fset.Position(pos),
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Fix:      fix,
+	})
+	return &l.diags[len(l.diags)-1]
+}
+
+// sortDiagnostics 按文件与位置排序诊断
+func (l *linter) sortDiagnostics() {
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		a, b := l.diags[i].Pos, l.diags[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// edit 返回将 [start, end) 替换为 text 的修改
+func (l *linter) edit(start, end token.Pos, text string) textEdit {
+	return textEdit{start: l.fset.Position(start).Offset, end: l.fset.Position(end).Offset, text: text}
+}
+
+// checkPackage 检查包名与 func main
+func (l *linter) checkPackage() {
+	if len(l.files) == 0 {
+		return
+	}
+	first := l.files[0]
+	if first.Name.Name != "main" {
+		l.report(first.Name.Pos(), SeverityError, RulePackageMain, "rename the package to main",
+			"package %s cannot be built with -buildmode=c-shared; it must be package main", first.Name.Name)
+	}
+	for _, file := range l.files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+				return
+			}
+		}
+	}
+
+	// func main 放在第一个 import "C" 的文件末尾
+	target := first
+	for _, file := range l.files {
+		if !ast.IsGenerated(file) && importsC(file) {
+			target = file
+			break
+		}
+	}
+	d := l.report(target.Package, SeverityError, RuleMissingMain, "add an empty func main() {}",
+		"package has no func main, which -buildmode=c-shared requires")
+	src := l.src[l.fset.Position(target.Package).Filename]
+	text := "\nfunc main() {}\n"
+	if !bytes.HasSuffix(src, []byte("\n")) {
+		text = "\n" + text
+	}
+	d.edits = []textEdit{{start: len(src), end: len(src), text: text}}
+}
+
+// checkExports 检查文件中的 //export 指令及其函数签名，以及没有附着到声明上的 //gophp: 指令
+func (l *linter) checkExports(file *ast.File) {
+	owners := make(map[*ast.CommentGroup]ast.Decl)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Doc != nil {
+				owners[decl.Doc] = decl
+			}
+		case *ast.GenDecl:
+			if decl.Doc != nil {
+				owners[decl.Doc] = decl
+			}
+			for _, spec := range decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.Doc != nil {
+					owners[ts.Doc] = decl
+				}
+			}
+		}
+	}
+
+	exports := false
+	for _, group := range file.Comments {
+		if group == file.Doc || group.Pos() < file.Name.End() {
+			continue
+		}
+		owner := owners[group]
+		for _, c := range group.List {
+			if owner == nil && strings.HasPrefix(c.Text, gophpDirective) {
+				l.checkDetachedDirective(file, group, c)
+				continue
+			}
+			if !strings.HasPrefix(c.Text, exportDirective) {
+				continue
+			}
+			fields := strings.Fields(strings.TrimPrefix(c.Text, exportDirective))
+			if len(fields) == 0 {
+				continue
+			}
+			exports = true
+			name := fields[0]
+
+			fn, _ := owner.(*ast.FuncDecl)
+			if fn == nil {
+				if next, _ := l.nextDecl(file, group).(*ast.FuncDecl); next != nil && owner == nil {
+					d := l.report(c.Pos(), SeverityError, RuleDetachedExport, "remove the blank line between //export and func",
+						"//export %s is separated from func %s by a blank line, so cgo ignores it", name, next.Name.Name)
+					d.edits = l.attach(group, next, next.Doc)
+				} else {
+					l.report(c.Pos(), SeverityError, RuleDetachedExport, "move the directive directly above the function it exports",
+						"//export %s is not attached to a function", name)
+				}
+				continue
+			}
+			if fn.Name.Name != name {
+				d := l.report(c.Pos(), SeverityError, RuleExportName, fmt.Sprintf("change the directive to //export %s", fn.Name.Name),
+					"//export %s does not match the function name %s", name, fn.Name.Name)
+				d.edits = []textEdit{l.edit(c.Pos(), c.End(), exportDirective+fn.Name.Name)}
+				continue
+			}
+			if fn.Recv == nil {
+				l.checkSignature(fn)
+			}
+		}
+	}
+
+	if exports && !importsC(file) {
+		d := l.report(file.Name.Pos(), SeverityError, RuleMissingImportC, `add import "C" to the file`,
+			`file uses //export but does not import "C", so cgo does not process it`)
+		d.edits = []textEdit{l.edit(file.Name.End(), file.Name.End(), "\n\nimport \"C\"")}
+	}
+}
+
+// checkDetachedDirective 检查与后续声明之间隔着空行的 //gophp: 指令，生成器会忽略这类指令
+func (l *linter) checkDetachedDirective(file *ast.File, group *ast.CommentGroup, c *ast.Comment) {
+	var (
+		name string
+		doc  *ast.CommentGroup
+	)
+	switch next := l.nextDecl(file, group).(type) {
+	case *ast.FuncDecl:
+		name, doc = "func "+next.Name.Name, next.Doc
+	case *ast.GenDecl:
+		if next.Tok != token.TYPE || len(next.Specs) != 1 {
+			return
+		}
+		name, doc = "type "+next.Specs[0].(*ast.TypeSpec).Name.Name, next.Doc
+	default:
+		return
+	}
+	d := l.report(c.Pos(), SeverityWarning, RuleDetachedDirective, "remove the blank line between the directive and the declaration",
+		"%s is separated from %s by a blank line, so gophpffi ignores it", strings.Fields(c.Text)[0], name)
+	d.edits = l.attach(group, l.nextDecl(file, group), doc)
+}
+
+// nextDecl 返回注释组之后的第一个声明，注释位于声明内部（例如函数体中）时返回 nil
+func (l *linter) nextDecl(file *ast.File, group *ast.CommentGroup) ast.Decl {
+	for _, decl := range file.Decls {
+		if decl.Pos() > group.End() {
+			return decl
+		}
+		if decl.End() > group.Pos() {
+			return nil
+		}
+	}
+	return nil
+}
+
+// attach 返回删除注释组与声明（或其文档注释 doc）之间空行的修改，两者之间有其他内容时返回 nil
+func (l *linter) attach(group *ast.CommentGroup, decl ast.Decl, doc *ast.CommentGroup) []textEdit {
+	end := decl.Pos()
+	if doc != nil {
+		end = doc.Pos()
+	}
+	src := l.src[l.fset.Position(group.Pos()).Filename]
+	from, to := l.fset.Position(group.End()).Offset, l.fset.Position(end).Offset
+	if len(bytes.TrimSpace(src[from:to])) > 0 {
+		return nil
+	}
+	return []textEdit{{start: from, end: to, text: "\n"}}
+}
+
+// importsC 判断文件是否 import "C"
+func importsC(file *ast.File) bool {
+	for _, spec := range file.Imports {
+		if spec.Path.Value == `"C"` {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSignature 检查直接 //export 的函数签名中 PHP 无法使用的类型
+// 末尾的 error 返回值由适配层转换为 C 字符串，不做检查
+func (l *linter) checkSignature(fn *ast.FuncDecl) {
+	errorType := types.Universe.Lookup("error").Type()
+	check := func(list *ast.FieldList, result bool) {
+		if list == nil {
+			return
+		}
+		for i, field := range list.List {
+			t := l.info.TypeOf(field.Type)
+			if t == nil || (result && i == len(list.List)-1 && types.Identical(t, errorType)) {
+				continue
+			}
+			l.checkType(fn.Name.Name, field.Type.Pos(), t, result)
+		}
+	}
+	check(fn.Type.Params, false)
+	check(fn.Type.Results, true)
+}
+
+// checkType 检查 //export 签名中的单个类型，C 类型（类型检查中为无效类型）不做检查
+func (l *linter) checkType(fn string, pos token.Pos, t types.Type, result bool) {
+	switch u := t.Underlying().(type) {
+	case *types.Map:
+		l.report(pos, SeverityError, RuleMapType, "bridge the function through JSON with //gophp:json (or json mode) instead of //export",
+			"%s: Go map %s cannot be passed through FFI", fn, t)
+	case *types.Interface:
+		l.report(pos, SeverityError, RuleInterfaceType, "use a concrete type, or //gophp:json for structured data",
+			"%s: interface type %s cannot be passed through FFI", fn, t)
+	case *types.Chan:
+		l.report(pos, SeverityError, RuleChanType, "expose functions that send or receive instead of the channel",
+			"%s: channel %s cannot be passed through FFI", fn, t)
+	case *types.Signature:
+		l.report(pos, SeverityError, RuleFuncType, "expose a regular exported function instead of a func value",
+			"%s: func value %s cannot be passed through FFI", fn, t)
+	case *types.Pointer:
+		if result && !isInvalid(u.Elem()) {
+			l.report(pos, SeverityError, RuleGoPointerResult, "return a value, a //gophp:handle type or memory allocated with C.malloc",
+				"%s returns Go pointer %s, which the cgo pointer check rejects at runtime", fn, t)
+		}
+	case *types.Slice:
+		// 数值、bool、string 切片与 []byte 返回值由适配层复制到 C 内存，其余切片的底层数组位于 Go 内存
+		p := Param{Type: t.String(), GoType: t}
+		switch {
+		case isGoBytes(p) || isCopyableSlice(p):
+		case result:
+			l.report(pos, SeverityError, RuleGoPointerResult, "return a numeric, bool, string or []byte slice, or bridge the result through JSON with //gophp:json",
+				"%s returns slice %s, whose backing array is Go memory that the cgo pointer check rejects at runtime", fn, t)
+		default:
+			// PHP 数组只能转换为数值、bool、string 切片，生成器会跳过该函数
+			l.report(pos, SeverityError, RuleSliceParam, "accept a numeric, bool, string or []byte slice, or bridge the parameter through JSON with //gophp:json",
+				"%s: slice parameter %s cannot be built from a PHP array", fn, t)
+		}
+	case *types.Struct:
+		if field, ok := goPointerField(u); ok {
+			l.report(pos, SeverityError, RuleStructGoPointer, "declare a //gophp:struct DTO with only numeric, bool and string fields, or use //gophp:json",
+				"%s: struct %s contains Go pointer field %s", fn, t, field)
+			return
+		}
+		l.report(pos, SeverityError, RuleStructType, "declare the struct with //gophp:struct and export the function with //gophp:export",
+			"%s: Go struct %s is not supported by //export", fn, t)
+	}
+}
+
+// checkStructs 检查 //gophp:struct 结构体中含有 Go 指针的字段
+func (l *linter) checkStructs(file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if _, marked := parseDirectives(doc)["struct"]; !marked || !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				t := l.info.TypeOf(field.Type)
+				if t == nil || !hasGoPointer(t) {
+					continue
+				}
+				for _, name := range field.Names {
+					if name.IsExported() {
+						l.report(name.Pos(), SeverityWarning, RuleStructGoPointer, "use numeric, bool or string fields, or tag the field php:\"-\"",
+							"//gophp:struct %s: field %s has type %s, which contains Go pointers; the struct will be skipped", ts.Name.Name, name.Name, t)
+					}
+				}
+			}
+		}
+	}
+}
+
+// goPointerField 返回结构体中第一个含有 Go 指针的字段名
+func goPointerField(st *types.Struct) (string, bool) {
+	for i := 0; i < st.NumFields(); i++ {
+		if hasGoPointer(st.Field(i).Type()) {
+			return st.Field(i).Name(), true
+		}
+	}
+	return "", false
+}
+
+// hasGoPointer 判断类型的值是否含有 Go 指针（string 在 DTO 中会被复制，不计入）
+func hasGoPointer(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return !isInvalid(u.Elem())
+	case *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return true
+	case *types.Array:
+		return hasGoPointer(u.Elem())
+	case *types.Struct:
+		_, ok := goPointerField(u)
+		return ok
+	}
+	return false
+}
+
+// isInvalid 判断类型是否无法解析（FakeImportC 下的 C.xxx 类型）
+func isInvalid(t types.Type) bool {
+	basic, ok := t.(*types.Basic)
+	return ok && basic.Kind() == types.Invalid
+}
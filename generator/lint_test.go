@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintSliceResults(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		want   bool // 是否报告 go-pointer-result
+	}{
+		{"int64", "[]int64", false},
+		{"float64", "[]float64", false},
+		{"bool", "[]bool", false},
+		{"bytes", "[]byte", false},
+		{"strings", "[]string", false},
+		{"pointers", "[]*Point", true},
+		{"nested", "[][]int", true},
+		{"structs", "[]Point", true},
+		{"maps", "[]map[string]int", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := filepath.Join(t.TempDir(), "svc.go")
+			src := "package main\n\nimport \"C\"\n\ntype Point struct{ X, Y int }\n\n" +
+				"//export Values\nfunc Values() " + tt.result + " { return nil }\n\nfunc main() {}\n"
+			if err := os.WriteFile(source, []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			result, err := Lint(Options{Source: source})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := false
+			for _, d := range result.Diagnostics {
+				if d.Rule == RuleGoPointerResult {
+					got = true
+				}
+			}
+			if got != tt.want {
+				t.Fatalf("go-pointer-result reported = %v, want %v (diagnostics: %+v)", got, tt.want, result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestLintSliceParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		param string
+		want  bool // 是否报告 slice-param
+	}{
+		{"int64", "[]int64", false},
+		{"bool", "[]bool", false},
+		{"bytes", "[]byte", false},
+		{"strings", "[]string", false},
+		{"pointers", "[]*Point", true},
+		{"nested", "[][]int64", true},
+		{"structs", "[]Point", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := filepath.Join(t.TempDir(), "svc.go")
+			src := "package main\n\nimport \"C\"\n\ntype Point struct{ X, Y int }\n\n" +
+				"//export Count\nfunc Count(values " + tt.param + ") int { return len(values) }\n\nfunc main() {}\n"
+			if err := os.WriteFile(source, []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			result, err := Lint(Options{Source: source})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := false
+			for _, d := range result.Diagnostics {
+				if d.Rule == RuleSliceParam {
+					got = true
+				}
+			}
+			if got != tt.want {
+				t.Fatalf("slice-param reported = %v, want %v (diagnostics: %+v)", got, tt.want, result.Diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ManifestVersion 是清单文件的格式版本，字段有不兼容的变化时递增
+const ManifestVersion = 1
+
+// ManifestFileName 返回服务对应的 API 清单文件名（<服务名>.manifest.json，与共享库的基本名称一致）
+func ManifestFileName(service string) string {
+	return service + ".manifest.json"
+}
+
+// Manifest 描述服务导出的全部 API，供部署工具与 PHP 运行时检查使用，无需重新解析 Go 源码
+// 清单中的路径均为相对路径（源码位置相对源码目录，PHP 文件相对清单所在目录），内容与生成时间无关
+type Manifest struct {
+	Version   int                `json:"version"`        // 清单格式版本（ManifestVersion）
+	Service   string             `json:"service"`        // 服务名
+	Package   string             `json:"package"`        // Go 包的导入路径
+	ABIHash   string             `json:"abi_hash"`       // PHP 通过 FFI 看到的 C 声明的 SHA-256，导出符号、参数与返回值类型或 DTO 布局变化时改变
+	PHP       ManifestPHP        `json:"php"`            // PHP 服务类
+	Free      string             `json:"free"`           // 释放 C 内存的导出函数
+	Functions []ManifestFunction `json:"functions"`      // 导出的函数（含句柄类型的构造函数、方法与释放函数）
+	Structs   []ManifestStruct   `json:"structs"`        // //gophp:struct 结构体
+	Handles   []ManifestHandle   `json:"handles"`        // //gophp:handle 句柄类型
+	Enums     []ManifestEnum     `json:"enums"`          // 包内命名类型及其导出常量
+	Constants []ManifestConst    `json:"constants"`      // 不属于枚举的导出常量
+	CDecl     string             `json:"c_declarations"` // 完整的 C 声明（FFI::cdef 使用的内容，ABIHash 的输入）
+}
+
+// ManifestPHP 描述生成的 PHP 服务类
+type ManifestPHP struct {
+	Namespace string `json:"namespace"`
+	Class     string `json:"class"`
+	File      string `json:"file"`    // PHP 服务类文件，相对清单所在目录
+	Runtime   string `json:"runtime"` // library 或 embedded
+}
+
+// ManifestFunction 描述一个导出函数
+type ManifestFunction struct {
+	Name        string          `json:"name"`                   // //export 或 //gophp:export 声明的导出名
+	GoName      string          `json:"go_name,omitempty"`      // Go 函数或方法名（没有 Close 方法的句柄释放函数为空）
+	PHPName     string          `json:"php_name"`               // PHP 方法名
+	Symbol      string          `json:"symbol"`                 // PHP 实际调用的 C 符号
+	Handle      string          `json:"handle,omitempty"`       // 所属的句柄类型
+	GoSignature string          `json:"go_signature,omitempty"` // Go 函数签名
+	CDecl       string          `json:"c_declaration"`          // C 符号的声明
+	Params      []ManifestParam `json:"params"`
+	Results     []ManifestParam `json:"results"`    // 返回值（不含末尾的 error，error 对应 Throws）
+	PHPReturn   string          `json:"php_return"` // PHP 方法的返回类型（PHPDoc 语法）
+	Throws      bool            `json:"throws"`     // 是否可能抛出 GoServiceException
+	Bridge      string          `json:"bridge"`     // 调用方式：direct（直接导出）、shim（适配函数）或 json（JSON 桥接）
+	Doc         string          `json:"doc,omitempty"`
+	Position    ManifestPos     `json:"position"`
+}
+
+// ManifestParam 描述一个参数或返回值
+type ManifestParam struct {
+	Name    string `json:"name"`
+	GoType  string `json:"go_type"`
+	PHPType string `json:"php_type"` // PHPDoc 语法的类型
+}
+
+// ManifestStruct 描述一个 DTO 结构体
+type ManifestStruct struct {
+	Name     string          `json:"name"`
+	PHPName  string          `json:"php_name"`
+	CName    string          `json:"c_name"`
+	Fields   []ManifestField `json:"fields"`
+	Doc      string          `json:"doc,omitempty"`
+	Position ManifestPos     `json:"position"`
+}
+
+// ManifestField 描述 DTO 的一个字段
+type ManifestField struct {
+	GoName  string `json:"go_name"`
+	PHPName string `json:"php_name"`
+	GoType  string `json:"go_type"`
+	CType   string `json:"c_type"`
+	PHPType string `json:"php_type"`
+}
+
+// ManifestHandle 描述一个句柄类型
+type ManifestHandle struct {
+	Name      string      `json:"name"`
+	PHPName   string      `json:"php_name"`
+	Functions []string    `json:"functions"` // 构造函数、方法与释放函数的导出名，详见 functions
+	Doc       string      `json:"doc,omitempty"`
+	Position  ManifestPos `json:"position"`
+}
+
+// ManifestEnum 描述一个枚举类型（底层为基础类型的命名类型）及其导出常量
+type ManifestEnum struct {
+	Name     string          `json:"name"`
+	GoType   string          `json:"go_type"` // 底层类型
+	Values   []ManifestConst `json:"values"`
+	Doc      string          `json:"doc,omitempty"`
+	Position ManifestPos     `json:"position"`
+}
+
+// ManifestConst 描述一个导出常量
+type ManifestConst struct {
+	Name     string      `json:"name"`
+	GoType   string      `json:"go_type"`
+	Value    any         `json:"value"` // 数值、字符串或布尔值，超出 int64 的整数为十进制字符串
+	Doc      string      `json:"doc,omitempty"`
+	Position ManifestPos `json:"position"`
+}
+
+// ManifestPos 是源码位置，File 相对源码目录
+type ManifestPos struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// buildManifest 根据解析结果构造服务的 API 清单
+func buildManifest(pkg *parsedPackage, opts generateOptions, importPath, manifestDir string) Manifest {
+	cdecl := generateCDeclarations(pkg, opts)
+	sum := sha256.Sum256([]byte(cdecl))
+	m := Manifest{
+		Version: ManifestVersion,
+		Service: opts.ServiceName,
+		Package: importPath,
+		ABIHash: "sha256:" + hex.EncodeToString(sum[:]),
+		PHP: ManifestPHP{
+			Namespace: opts.Namespace,
+			Class:     opts.ClassName,
+			File:      relSlash(manifestDir, opts.PHPFile),
+			Runtime:   opts.Runtime,
+		},
+		Free:      freeFuncName(opts.ServiceName),
+		Functions: []ManifestFunction{},
+		Structs:   []ManifestStruct{},
+		Handles:   []ManifestHandle{},
+		Enums:     []ManifestEnum{},
+		Constants: []ManifestConst{},
+		CDecl:     cdecl,
+	}
+
+	for _, exp := range pkg.funcs() {
+		fn := ManifestFunction{
+			Name:        exp.Name,
+			GoName:      exp.GoName,
+			PHPName:     exp.PHPName,
+			Symbol:      ffiSymbol(exp, opts.ServiceName),
+			GoSignature: exp.Signature,
+			CDecl:       cFuncDeclaration(exp, opts.ServiceName),
+			Params:      []ManifestParam{},
+			Results:     []ManifestParam{},
+			PHPReturn:   returnPHPDoc(exp, opts.ResultMode),
+			Throws:      canThrow(exp),
+			Bridge:      "direct",
+			Doc:         exp.Doc,
+			Position:    manifestPos(opts.SourceDir, exp.Pos.Filename, exp.Pos.Line, exp.Pos.Column),
+		}
+		if h := handleOf(exp); h != nil {
+			fn.Handle = h.Name
+		}
+		switch {
+		case exp.JSON:
+			fn.Bridge = "json"
+		case needsShim(exp):
+			fn.Bridge = "shim"
+		}
+		for _, p := range exp.Params {
+			fn.Params = append(fn.Params, ManifestParam{Name: p.Name, GoType: p.Type, PHPType: paramPHPDoc(exp, p)})
+		}
+		for _, r := range valueResults(exp) {
+			fn.Results = append(fn.Results, ManifestParam{Name: r.Name, GoType: r.Type, PHPType: resultPHPDoc(exp, r)})
+		}
+		m.Functions = append(m.Functions, fn)
+	}
+
+	for i := range pkg.Structs {
+		def := &pkg.Structs[i]
+		st := ManifestStruct{
+			Name:     def.Name,
+			PHPName:  def.PHPName,
+			CName:    structCName(def, opts.ServiceName),
+			Fields:   []ManifestField{},
+			Doc:      def.Comment,
+			Position: manifestPos(opts.SourceDir, def.Pos.Filename, def.Pos.Line, def.Pos.Column),
+		}
+		for _, f := range def.Fields {
+			st.Fields = append(st.Fields, ManifestField{
+				GoName:  f.GoName,
+				PHPName: f.PHPName,
+				GoType:  f.GoType.String(),
+				CType:   structCFieldTypes[f.Kind],
+				PHPType: structFieldPHPType(f),
+			})
+		}
+		m.Structs = append(m.Structs, st)
+	}
+
+	for _, h := range pkg.Handles {
+		mh := ManifestHandle{
+			Name:     h.Name,
+			PHPName:  h.PHPName,
+			Doc:      h.Comment,
+			Position: manifestPos(opts.SourceDir, h.Pos.Filename, h.Pos.Line, h.Pos.Column),
+		}
+		for _, exp := range h.funcs() {
+			mh.Functions = append(mh.Functions, exp.Name)
+		}
+		m.Handles = append(m.Handles, mh)
+	}
+
+	for _, enum := range pkg.Enums {
+		me := ManifestEnum{
+			Name:     enum.Name,
+			GoType:   enum.GoType,
+			Values:   []ManifestConst{},
+			Doc:      enum.Comment,
+			Position: manifestPos(opts.SourceDir, enum.Pos.Filename, enum.Pos.Line, enum.Pos.Column),
+		}
+		for _, c := range enum.Values {
+			me.Values = append(me.Values, manifestConst(c, opts.SourceDir))
+		}
+		m.Enums = append(m.Enums, me)
+	}
+	for _, c := range pkg.Consts {
+		m.Constants = append(m.Constants, manifestConst(c, opts.SourceDir))
+	}
+	return m
+}
+
+// manifestConst 将常量定义转换为清单中的常量
+func manifestConst(c ConstDef, dir string) ManifestConst {
+	return ManifestConst{
+		Name:     c.Name,
+		GoType:   c.GoType,
+		Value:    c.Value,
+		Doc:      c.Comment,
+		Position: manifestPos(dir, c.Pos.Filename, c.Pos.Line, c.Pos.Column),
+	}
+}
+
+// writeManifest 将清单写入 dir/<服务名>.manifest.json
+func writeManifest(m Manifest, dir string) (string, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	outputFile := filepath.Join(dir, ManifestFileName(m.Service))
+	return outputFile, os.WriteFile(outputFile, append(data, '\n'), 0644)
+}
+
+// manifestPos 返回相对 dir 的源码位置
+func manifestPos(dir, filename string, line, column int) ManifestPos {
+	return ManifestPos{File: relSlash(dir, filename), Line: line, Column: column}
+}
+
+// relSlash 返回 path 相对 base 的路径（使用 / 分隔），无法计算时返回原路径
+func relSlash(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		rel = path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,406 @@
+package generator
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// buffersVar 是 PHP 方法中保存临时 C 缓冲区的变量名
+// 缓冲区在方法返回前一直被引用，保证 FFI 调用期间内存有效
+const buffersVar = "buffers"
+
+// isGoString 判断参数或返回值是否为 Go string（cgo 头文件中的 GoString 结构体）
+func isGoString(p Param) bool {
+	if p.GoType != nil {
+		basic, ok := p.GoType.Underlying().(*types.Basic)
+		return ok && basic.Kind() == types.String
+	}
+	return p.Type == "string"
+}
+
+// isGoBytes 判断参数或返回值是否为 []byte
+func isGoBytes(p Param) bool {
+	if p.GoType != nil {
+		slice, ok := p.GoType.Underlying().(*types.Slice)
+		if !ok {
+			return false
+		}
+		basic, ok := slice.Elem().Underlying().(*types.Basic)
+		return ok && basic.Kind() == types.Byte
+	}
+	return p.Type == "[]byte" || p.Type == "[]uint8"
+}
+
+// isCString 判断参数或返回值是否为 *C.char
+// 返回 *C.char 的函数约定由调用方（PHP）负责释放内存
+func isCString(p Param) bool {
+	return strings.ReplaceAll(p.Type, " ", "") == "*C.char"
+}
+
+// sliceElemCTypes 将 Go 切片元素的基础类型映射为 cgo 头文件中的 C 类型
+var sliceElemCTypes = map[types.BasicKind]string{
+	types.Int:     "GoInt",
+	types.Int8:    "GoInt8",
+	types.Int16:   "GoInt16",
+	types.Int32:   "GoInt32",
+	types.Int64:   "GoInt64",
+	types.Uint:    "GoUint",
+	types.Uint8:   "GoUint8",
+	types.Uint16:  "GoUint16",
+	types.Uint32:  "GoUint32",
+	types.Uint64:  "GoUint64",
+	types.Uintptr: "GoUintptr",
+	types.Float32: "GoFloat32",
+	types.Float64: "GoFloat64",
+	types.Bool:    "bool",
+	types.String:  "GoString",
+}
+
+// sliceElem 返回切片参数的元素基础类型
+func sliceElem(p Param) (*types.Basic, bool) {
+	if p.GoType == nil {
+		return nil, false
+	}
+	slice, ok := p.GoType.Underlying().(*types.Slice)
+	if !ok {
+		return nil, false
+	}
+	basic, ok := slice.Elem().Underlying().(*types.Basic)
+	if !ok {
+		return nil, false
+	}
+	if _, supported := sliceElemCTypes[basic.Kind()]; !supported {
+		return nil, false
+	}
+	return basic, true
+}
+
+// isGoSlice 判断参数是否为可以与 PHP 数组互相转换的切片（[]byte 除外）
+func isGoSlice(p Param) bool {
+	_, ok := sliceElem(p)
+	return ok && !isGoBytes(p)
+}
+
+// unsupportedSliceParam 返回函数中 PHP 数组无法构造的切片参数（例如 []*T、[][]int64），JSON 桥接的参数除外
+func unsupportedSliceParam(exp ExportedFunc) (Param, bool) {
+	for _, p := range exp.Params {
+		if p.GoType == nil || isJSONValue(exp, p) || isGoBytes(p) || isGoSlice(p) {
+			continue
+		}
+		if _, ok := p.GoType.Underlying().(*types.Slice); ok {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// isCopyableSlice 判断切片返回值能否由适配层复制到 C 内存
+// []string 的每个元素也被复制到 C 内存，数组元素为指向 C 内存的 GoString
+func isCopyableSlice(p Param) bool {
+	return isGoSlice(p)
+}
+
+// hasStringSliceResults 判断导出函数列表中是否存在 []string 返回值
+func hasStringSliceResults(exports []ExportedFunc) bool {
+	for _, exp := range exports {
+		for _, r := range valueResults(exp) {
+			if isCopyableSlice(r) && sliceElemCType(r) == "GoString" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sliceElemCType 返回切片元素对应的 C 类型
+func sliceElemCType(p Param) string {
+	elem, _ := sliceElem(p)
+	return sliceElemCTypes[elem.Kind()]
+}
+
+// slicePHPDoc 返回切片在 PHPDoc 中的类型，例如 list<int>
+func slicePHPDoc(p Param) string {
+	elem, _ := sliceElem(p)
+	var phpType string
+	switch {
+	case elem.Info()&types.IsInteger != 0:
+		phpType = "int"
+	case elem.Info()&types.IsFloat != 0:
+		phpType = "float"
+	case elem.Kind() == types.Bool:
+		phpType = "bool"
+	default:
+		phpType = "string"
+	}
+	return fmt.Sprintf("list<%s>", phpType)
+}
+
+// isOwnedResult 判断返回值是否需要由适配层复制到 C 内存后返回
+func isOwnedResult(p Param) bool {
+	return isGoString(p) || isGoBytes(p) || isCopyableSlice(p)
+}
+
+// hasStringParams 判断导出函数列表中是否存在 string 参数（包括 []string）
+func hasStringParams(exports []ExportedFunc) bool {
+	for _, exp := range exports {
+		for _, p := range exp.Params {
+			if isGoString(p) || isJSONValue(exp, p) || isGoSlice(p) && sliceElemCType(p) == "GoString" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasCMemoryResults 判断是否有返回值需要在 PHP 端复制并释放 C 内存
+func hasCMemoryResults(exports []ExportedFunc) bool {
+	for _, exp := range exports {
+		if canThrow(exp) {
+			return true
+		}
+		for _, r := range valueResults(exp) {
+			if isOwnedResult(r) || isCString(r) || isStructValue(exp, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// needsBuffers 判断 PHP 方法是否需要为参数分配临时缓冲区
+func needsBuffers(exp ExportedFunc) bool {
+	for _, p := range exp.Params {
+		if isGoString(p) || isGoBytes(p) || isGoSlice(p) || isJSONValue(exp, p) || isStructValue(exp, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// phpArgValue 将 PHP 参数转换为传给 FFI 的表达式
+func phpArgValue(exp ExportedFunc, p Param) string {
+	switch {
+	case isStructValue(exp, p):
+		return fmt.Sprintf("$this->toC%s($%s, $%s)", p.Struct.Name, p.Name, buffersVar)
+	case isJSONValue(exp, p):
+		return fmt.Sprintf("$this->toGoString($this->toJSON($%s, %t), $%s)", p.Name, isObjectType(p), buffersVar)
+	case isGoString(p):
+		return fmt.Sprintf("$this->toGoString($%s, $%s)", p.Name, buffersVar)
+	case isGoBytes(p):
+		return fmt.Sprintf("$this->toGoBytes($%s, $%s)", p.Name, buffersVar)
+	case isGoSlice(p):
+		return fmt.Sprintf("$this->toGoSlice($%s, '%s', $%s)", p.Name, sliceElemCType(p), buffersVar)
+	}
+	return "$" + p.Name
+}
+
+// paramPHPHint 返回参数的 PHP 类型提示（无法确定时为空）
+func paramPHPHint(exp ExportedFunc, p Param) string {
+	if isStructValue(exp, p) {
+		return p.Struct.PHPName
+	}
+	if isJSONValue(exp, p) {
+		return "array"
+	}
+	if isGoBytes(p) {
+		return "string"
+	}
+	return cTypeToPHPDoc(p.Type)
+}
+
+// paramPHPDoc 返回参数在 PHPDoc 中的类型
+func paramPHPDoc(exp ExportedFunc, p Param) string {
+	switch {
+	case isStructValue(exp, p):
+		return p.Struct.PHPName
+	case isJSONValue(exp, p):
+		return "array"
+	case isGoBytes(p):
+		return "string"
+	case isGoSlice(p):
+		return slicePHPDoc(p)
+	}
+	return cTypeToPHPType(p.Type)
+}
+
+// resultPHPHint 返回返回值的 PHP 类型提示（无法确定时为空）
+func resultPHPHint(exp ExportedFunc, p Param) string {
+	if p.Handle != nil {
+		return "int"
+	}
+	if isStructValue(exp, p) {
+		return p.Struct.PHPName
+	}
+	if isJSONValue(exp, p) {
+		return "?array"
+	}
+	if isGoBytes(p) {
+		return "string"
+	}
+	return cTypeToPHPDoc(p.Type)
+}
+
+// resultPHPDoc 返回返回值在 PHPDoc 中的类型
+func resultPHPDoc(exp ExportedFunc, p Param) string {
+	switch {
+	case p.Handle != nil:
+		return "int"
+	case isStructValue(exp, p):
+		return p.Struct.PHPName
+	case isJSONValue(exp, p):
+		return "array|null"
+	case isGoBytes(p):
+		return "string"
+	case isCopyableSlice(p):
+		return slicePHPDoc(p)
+	}
+	return cTypeToPHPType(p.Type)
+}
+
+// hasSliceParams 判断导出函数列表中是否存在切片或 []byte 参数
+func hasSliceParams(exports []ExportedFunc) bool {
+	for _, exp := range exports {
+		for _, p := range exp.Params {
+			if isGoBytes(p) || isGoSlice(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasSliceResults 判断导出函数列表中是否存在可复制的切片返回值
+func hasSliceResults(exports []ExportedFunc) bool {
+	for _, exp := range exports {
+		for _, r := range valueResults(exp) {
+			if isCopyableSlice(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generateStringHelpers 生成将 PHP 字符串转换为 GoString 的私有方法
+func generateStringHelpers() string {
+	return `    /**
+     * 将 PHP 字符串转换为 GoString
+     * 底层缓冲区被追加到 $buffers 中，调用方需在 FFI 调用结束前保持其引用
+     * @param string $value
+     * @param array $buffers
+     * @return \FFI\CData
+     */
+    private function toGoString(string $value, array &$buffers): \FFI\CData {
+        $length = strlen($value);
+        $buffer = $this->ffi->new('char[' . ($length + 1) . ']');
+        if ($length > 0) {
+            \FFI::memcpy($buffer, $value, $length);
+        }
+        $buffers[] = $buffer;
+
+        $goString = $this->ffi->new('GoString');
+        $goString->p = $this->ffi->cast('char *', \FFI::addr($buffer));
+        $goString->n = $length;
+        return $goString;
+    }
+`
+}
+
+// generateSliceHelpers 生成将 PHP 数组或二进制字符串转换为 GoSlice 的私有方法
+func generateSliceHelpers() string {
+	return `    /**
+     * 将 PHP 数组转换为 GoSlice
+     * 元素被复制到类型为 $type 的 C 数组中，数组被追加到 $buffers 中，调用方需在 FFI 调用结束前保持其引用
+     * @param array $values
+     * @param string $type C 元素类型，例如 GoInt64、GoFloat64、GoString
+     * @param array $buffers
+     * @return \FFI\CData
+     */
+    private function toGoSlice(array $values, string $type, array &$buffers): \FFI\CData {
+        $length = count($values);
+        $data = $this->ffi->new($type . '[' . max($length, 1) . ']');
+        $i = 0;
+        foreach ($values as $value) {
+            $data[$i++] = $type === 'GoString' ? $this->toGoString((string)$value, $buffers) : $value;
+        }
+        $buffers[] = $data;
+
+        $slice = $this->ffi->new('GoSlice');
+        $slice->data = $this->ffi->cast('void *', \FFI::addr($data));
+        $slice->len = $length;
+        $slice->cap = $length;
+        return $slice;
+    }
+
+    /**
+     * 将 PHP 二进制字符串转换为 []byte 对应的 GoSlice
+     * @param string $value
+     * @param array $buffers
+     * @return \FFI\CData
+     */
+    private function toGoBytes(string $value, array &$buffers): \FFI\CData {
+        $length = strlen($value);
+        $data = $this->ffi->new('char[' . max($length, 1) . ']');
+        if ($length > 0) {
+            \FFI::memcpy($data, $value, $length);
+        }
+        $buffers[] = $data;
+
+        $slice = $this->ffi->new('GoSlice');
+        $slice->data = $this->ffi->cast('void *', \FFI::addr($data));
+        $slice->len = $length;
+        $slice->cap = $length;
+        return $slice;
+    }
+`
+}
+
+// generateSliceResultHelper 生成将 C 数组复制为 PHP 数组并释放的私有方法
+func generateSliceResultHelper(opts generateOptions) string {
+	return fmt.Sprintf(`    /**
+     * 将适配层返回的 C 数组复制为 PHP 数组并立即释放
+     * GoString 数组中每个元素指向的字符串内存也会被释放
+     * @param \FFI\CData|null $pointer
+     * @param int $length
+     * @param string $type C 元素类型
+     * @return array
+     */
+    private function takeCArray($pointer, int $length, string $type): array {
+        if ($pointer === null) {
+            return [];
+        }
+        try {
+            $data = $this->ffi->cast($type . ' *', $pointer);
+            $values = [];
+            for ($i = 0; $i < $length; $i++) {
+                $values[] = $type === 'GoString' ? $this->takeCString($data[$i]->p, $data[$i]->n) : $data[$i];
+            }
+            return $values;
+        } finally {
+            $this->ffi->%s($pointer);
+        }
+    }
+`, freeFuncName(opts.ServiceName))
+}
+
+// generateCMemoryHelpers 生成复制并释放 C 内存的私有方法
+func generateCMemoryHelpers(opts generateOptions) string {
+	return fmt.Sprintf(`    /**
+     * 将 Go 分配的 C 内存复制为 PHP 字符串并立即释放
+     * @param \FFI\CData|null $pointer
+     * @param int|null $length 为 null 时按 NUL 结尾字符串读取
+     * @return string
+     */
+    private function takeCString($pointer, ?int $length = null): string {
+        if ($pointer === null) {
+            return '';
+        }
+        try {
+            return $length === null ? \FFI::string($pointer) : \FFI::string($pointer, $length);
+        } finally {
+            $this->ffi->%s($pointer);
+        }
+    }
+`, freeFuncName(opts.ServiceName))
+}
@@ -0,0 +1,58 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSkipsUnsupportedSliceParams(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "svc.go")
+	src := `package main
+
+import "C"
+
+type Point struct{ X, Y int }
+
+//export Sum
+func Sum(values []int64) int64 { return 0 }
+
+//export Count
+func Count(points []*Point) int { return len(points) }
+
+//gophp:export
+func Flatten(rows [][]int64) int { return len(rows) }
+
+func main() {}
+`
+	if err := os.WriteFile(source, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Generate(Options{Source: source, ServiceName: "Svc", OutputDir: filepath.Join(dir, "dist")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, exp := range result.Exports {
+		names = append(names, exp.Name)
+	}
+	if got := strings.Join(names, ","); got != "Sum" {
+		t.Fatalf("Exports = %s, want Sum", got)
+	}
+	warnings := strings.Join(result.Warnings, "\n")
+	for _, name := range []string{"Count", "Flatten"} {
+		if !strings.Contains(warnings, "skipping "+name) {
+			t.Errorf("warnings do not mention %s:\n%s", name, warnings)
+		}
+	}
+	php, err := os.ReadFile(result.PHPFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lower := strings.ToLower(string(php))
+	if strings.Contains(lower, "function count(") || strings.Contains(lower, "function flatten(") {
+		t.Fatalf("PHP class still exposes a skipped function:\n%s", php)
+	}
+}
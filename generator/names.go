@@ -0,0 +1,96 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// 命名模板的默认值，与早期版本的 ThinkPHP 风格布局保持一致
+const (
+	DefaultNamespace = `app\{{.Snake}}\service`
+	DefaultClassName = "{{.Pascal}}Service"
+	DefaultBaseClass = `Wuwuseo\PhpffiGoLibrary\GoLibraryBase`
+)
+
+// phpIdentifier 匹配 PHP 类名与命名空间中的单段名称
+var phpIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NameData 是命名模板（命名空间、类名、输出路径）中可用的服务名形式
+// 例如服务名 user_profile 或 UserProfile：
+//
+//	{{.Name}}   原样
+//	{{.Pascal}} UserProfile
+//	{{.Camel}}  userProfile
+//	{{.Snake}}  user_profile
+//	{{.Kebab}}  user-profile
+//	{{.Lower}}  userprofile
+type NameData struct {
+	Name   string
+	Pascal string
+	Camel  string
+	Snake  string
+	Kebab  string
+	Lower  string
+}
+
+// newNameData 返回服务名的各种形式，缩写词由 n 决定
+func newNameData(service string, n *namer) NameData {
+	pascal := n.pascal(service)
+	snake := n.snake(service)
+	return NameData{
+		Name:   service,
+		Pascal: pascal,
+		Camel:  n.camel(service),
+		Snake:  snake,
+		Kebab:  strings.ReplaceAll(snake, "_", "-"),
+		Lower:  strings.ToLower(pascal),
+	}
+}
+
+// ExpandName 用服务名展开命名模板（text/template 语法，见 NameData），使用内置缩写词
+func ExpandName(tmpl, service string) (string, error) {
+	return expandName(tmpl, service, defaultNamer)
+}
+
+// expandName 用服务名展开命名模板，n 决定 NameData 中识别的缩写词
+func expandName(tmpl, service string, n *namer) (string, error) {
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid name template %q: %w", tmpl, err)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, newNameData(service, n)); err != nil {
+		return "", fmt.Errorf("expand name template %q: %w", tmpl, err)
+	}
+	return sb.String(), nil
+}
+
+// expandPHPName 展开类名或命名空间模板并校验结果，tmpl 为空时使用 fallback
+// qualified 为 true 时允许以反斜杠分隔的多段名称（首尾的反斜杠会被去掉）
+func expandPHPName(tmpl, fallback, service string, qualified bool, n *namer) (string, error) {
+	if tmpl == "" {
+		tmpl = fallback
+	}
+	name, err := expandName(tmpl, service, n)
+	if err != nil {
+		return "", err
+	}
+	name = strings.Trim(name, `\`)
+	parts := []string{name}
+	if qualified {
+		parts = strings.Split(name, `\`)
+	}
+	for _, part := range parts {
+		if !phpIdentifier.MatchString(part) {
+			return "", fmt.Errorf("%q (from template %q) is not a valid PHP name", name, tmpl)
+		}
+	}
+	return name, nil
+}
+
+// phpShortName 返回完全限定类名的最后一段
+func phpShortName(name string) string {
+	return name[strings.LastIndex(name, `\`)+1:]
+}
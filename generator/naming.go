@@ -0,0 +1,281 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// 命名策略取值
+const (
+	NamingKeep   = "keep"   // 保持原样
+	NamingPascal = "pascal" // PascalCase
+	NamingCamel  = "camel"  // camelCase
+	NamingSnake  = "snake"  // snake_case
+	NamingLower  = "lower"  // 全部小写
+)
+
+// Naming 描述生成的 PHP 名称的命名策略
+type Naming struct {
+	Methods    string   // PHP 方法名：keep（默认，与 Go 导出名一致）、camel 或 snake
+	Classes    string   // 服务类、DTO、句柄与结果类名：keep（默认）或 pascal
+	Namespaces string   // 命名空间的每一段：keep（默认）、pascal、snake 或 lower
+	Acronyms   []string // 额外的缩写词，在 Pascal/Camel 形式中保持全大写（内置 HTTP、ID、JSON 等常见缩写）
+}
+
+// defaultAcronyms 是内置的缩写词（与 Go 的常见首字母缩写一致）
+var defaultAcronyms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "FFI", "GUID", "HTML", "HTTP", "HTTPS",
+	"ID", "IP", "JSON", "LHS", "PHP", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH",
+	"TCP", "TLS", "TTL", "UDP", "UI", "UID", "URI", "URL", "UTF8", "UUID", "VM", "XML", "XMPP",
+	"XSRF", "XSS",
+}
+
+// namer 按命名策略转换名称
+type namer struct {
+	policy   Naming
+	acronyms map[string]bool
+}
+
+// defaultNamer 使用内置缩写词与默认策略，用于 C 符号、文件名等与配置无关的名称
+var defaultNamer = newNamer(Naming{})
+
+// newNamer 创建命名器，未设置的策略取 keep
+func newNamer(policy Naming) *namer {
+	n := &namer{policy: policy, acronyms: make(map[string]bool)}
+	for _, a := range append(append([]string{}, defaultAcronyms...), policy.Acronyms...) {
+		n.acronyms[strings.ToUpper(a)] = true
+	}
+	return n
+}
+
+// validate 检查策略取值
+func (p Naming) validate() error {
+	check := func(field, value string, allowed ...string) error {
+		if value == "" {
+			return nil
+		}
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid %s naming policy %q (expected one of %s)", field, value, strings.Join(allowed, ", "))
+	}
+	if err := check("method", p.Methods, NamingKeep, NamingCamel, NamingSnake); err != nil {
+		return err
+	}
+	if err := check("class", p.Classes, NamingKeep, NamingPascal); err != nil {
+		return err
+	}
+	return check("namespace", p.Namespaces, NamingKeep, NamingPascal, NamingSnake, NamingLower)
+}
+
+// splitWords 将标识符拆分为单词，能识别缩写：
+// HTTPServer -> [HTTP Server]、userID -> [user ID]、user_profile -> [user profile]、Base64Encode -> [Base64 Encode]、UserIDs -> [User IDs]
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			words = append(words, string(runes[start:end]))
+		}
+		start = -1
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		if !unicode.IsUpper(r) {
+			continue
+		}
+		switch {
+		case unicode.IsLower(prev), unicode.IsDigit(prev):
+			// fooBar、Base64Encode
+			flush(i)
+			start = i
+		case unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !pluralSuffix(runes, i+1):
+			// 缩写后紧跟新单词：HTTPServer 在 S 之前拆分（IDs 这样的复数缩写不拆分）
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return words
+}
+
+// pluralSuffix 判断 runes[i] 是否为单独的复数后缀 s（其后为结尾或非小写字母），例如 IDs、URLsFor
+func pluralSuffix(runes []rune, i int) bool {
+	return runes[i] == 's' && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+}
+
+// pascalWord 将单词转换为首字母大写，缩写词保持全大写
+// 缩写后跟数字或复数 s 时同样按缩写处理，例如 id2 -> ID2、ids -> IDs
+func (n *namer) pascalWord(word string) string {
+	upper := strings.ToUpper(word)
+	if n.acronyms[upper] {
+		return upper
+	}
+	if base := strings.TrimRight(upper, "0123456789"); base != upper && n.acronyms[base] {
+		return upper
+	}
+	if base, ok := strings.CutSuffix(upper, "S"); ok && n.acronyms[base] {
+		return base + "s"
+	}
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// pascal 转换为 PascalCase，例如 http_server -> HTTPServer、user_profile -> UserProfile
+func (n *namer) pascal(s string) string {
+	var sb strings.Builder
+	for _, word := range splitWords(s) {
+		sb.WriteString(n.pascalWord(word))
+	}
+	return sb.String()
+}
+
+// camel 转换为 camelCase，开头的缩写整体小写，例如 HTTPServer -> httpServer、UserID -> userID
+func (n *namer) camel(s string) string {
+	var sb strings.Builder
+	for i, word := range splitWords(s) {
+		if i == 0 {
+			sb.WriteString(strings.ToLower(word))
+			continue
+		}
+		sb.WriteString(n.pascalWord(word))
+	}
+	return sb.String()
+}
+
+// snake 转换为 snake_case，例如 HTTPServer -> http_server
+func (n *namer) snake(s string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// method 按方法名策略转换名称
+func (n *namer) method(name string) string {
+	switch n.policy.Methods {
+	case NamingCamel:
+		return n.camel(name)
+	case NamingSnake:
+		return n.snake(name)
+	}
+	return name
+}
+
+// class 按类名策略转换名称
+func (n *namer) class(name string) string {
+	if n.policy.Classes == NamingPascal {
+		return n.pascal(name)
+	}
+	return name
+}
+
+// namespace 按命名空间策略逐段转换
+func (n *namer) namespace(name string) string {
+	parts := strings.Split(name, `\`)
+	for i, part := range parts {
+		switch n.policy.Namespaces {
+		case NamingPascal:
+			parts[i] = n.pascal(part)
+		case NamingSnake:
+			parts[i] = n.snake(part)
+		case NamingLower:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, `\`)
+}
+
+// applyNaming 为导出函数、DTO 与句柄类型确定 PHP 名称
+// 函数上的 //gophp:name 指令优先于方法名策略；句柄方法按 Go 方法名计算
+func applyNaming(pkg *parsedPackage, n *namer) error {
+	phpMethod := func(exp *ExportedFunc, name string) {
+		exp.PHPName = n.method(name)
+		if override := exp.Directives["name"]; override != "" {
+			if phpIdentifier.MatchString(override) {
+				exp.PHPName = override
+			} else {
+				pkg.Warnings = append(pkg.Warnings, fmt.Sprintf("%s: ignoring //gophp:name %q: not a valid PHP method name", exp.Pos, override))
+			}
+		}
+		exp.ResultClass = n.class(exp.Name + "Result")
+	}
+
+	// 服务类中生成的运行时与辅助方法
+	reserved := make(map[string]bool)
+	for _, name := range serviceHelperMethods {
+		reserved[strings.ToLower(name)] = true
+	}
+	for _, def := range pkg.Structs {
+		reserved[strings.ToLower("toC"+def.Name)] = true
+		reserved[strings.ToLower("fromC"+def.Name)] = true
+	}
+
+	seen := make(map[string]string)
+	for i := range pkg.Exports {
+		exp := &pkg.Exports[i]
+		phpMethod(exp, exp.Name)
+		// PHP 方法名不区分大小写
+		key := strings.ToLower(exp.PHPName)
+		if reserved[key] {
+			return fmt.Errorf("%s maps to PHP method %s, which is reserved for a generated helper; rename it with //gophp:name", exp.Name, exp.PHPName)
+		}
+		if other, ok := seen[key]; ok {
+			return fmt.Errorf("%s and %s both map to PHP method %s", other, exp.Name, exp.PHPName)
+		}
+		seen[key] = exp.Name
+	}
+
+	for i := range pkg.Structs {
+		pkg.Structs[i].PHPName = n.class(pkg.Structs[i].Name)
+	}
+	for _, h := range pkg.Handles {
+		h.PHPName = n.class(h.Name)
+		// 服务类中的句柄函数仅供句柄类内部调用，保持导出名
+		h.Constructor.PHPName = h.Constructor.Name
+		h.Constructor.ResultClass = n.class(h.Constructor.Name + "Result")
+		phpMethod(&h.Close, "Close")
+		// handle() 与构造、析构函数是句柄类自身的方法
+		methods := map[string]string{"handle": "handle", "__construct": "__construct", "__destruct": "__destruct"}
+		methods[strings.ToLower(h.Close.PHPName)] = "Close"
+		for j := range h.Methods {
+			m := &h.Methods[j]
+			phpMethod(m, m.GoName)
+			key := strings.ToLower(m.PHPName)
+			if other, ok := methods[key]; ok {
+				return fmt.Errorf("%s.%s and %s.%s both map to PHP method %s", h.Name, other, h.Name, m.GoName, m.PHPName)
+			}
+			methods[key] = m.GoName
+		}
+	}
+	return nil
+}
+
+// serviceHelperMethods 是生成的服务类中可能出现的运行时与辅助方法（DTO 转换方法 toC<Name>/fromC<Name> 另行检查）
+var serviceHelperMethods = []string{
+	"__construct", "getBaseDir", "libraryPath", "preload",
+	"toGoString", "toGoSlice", "toGoBytes", "toCBuffer", "toJSON",
+	"takeCString", "takeCArray", "throwIfGoError",
+}
+
+// serviceMethodName 返回函数在服务类中的方法名，句柄函数保持导出名
+func serviceMethodName(exp ExportedFunc) string {
+	if handleOf(exp) != nil || exp.PHPName == "" {
+		return exp.Name
+	}
+	return exp.PHPName
+}
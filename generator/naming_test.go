@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // 以空格连接的单词
+	}{
+		{"", ""},
+		{"a", "a"},
+		{"ID", "ID"},
+		{"ID2", "ID2"},
+		{"HTTPServer", "HTTP Server"},
+		{"userID", "user ID"},
+		{"UserIDs", "User IDs"},
+		{"URLsFor", "URLs For"},
+		{"GetHTTPStatusID", "Get HTTP Status ID"},
+		{"HTTP2Server", "HTTP2 Server"},
+		{"UTF8String", "UTF8 String"},
+		{"Base64Encode", "Base64 Encode"},
+		{"ID2Name", "ID2 Name"},
+		{"user_profile", "user profile"},
+		{"__user__id__", "user id"},
+		{"kebab-case-name", "kebab case name"},
+		{"AString", "A String"},
+		{"getAs", "get As"},
+		{"ÜberName", "Über Name"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(splitWords(tt.in), " "); got != tt.want {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNamerConversions(t *testing.T) {
+	n := newNamer(Naming{Acronyms: []string{"sku"}})
+	tests := []struct {
+		in                   string
+		pascal, camel, snake string
+	}{
+		{"HTTPServer", "HTTPServer", "httpServer", "http_server"},
+		{"http_server", "HTTPServer", "httpServer", "http_server"},
+		{"ID2", "ID2", "id2", "id2"},
+		{"user_id2", "UserID2", "userID2", "user_id2"},
+		{"UserIDs", "UserIDs", "userIDs", "user_ids"},
+		{"user_ids", "UserIDs", "userIDs", "user_ids"},
+		{"GetHTTPStatusID", "GetHTTPStatusID", "getHTTPStatusID", "get_http_status_id"},
+		{"http_api", "HTTPAPI", "httpAPI", "http_api"},
+		{"sku_list", "SKUList", "skuList", "sku_list"},
+		{"Base64Encode", "Base64Encode", "base64Encode", "base64_encode"},
+		{"user_profile", "UserProfile", "userProfile", "user_profile"},
+	}
+	for _, tt := range tests {
+		if got := n.pascal(tt.in); got != tt.pascal {
+			t.Errorf("pascal(%q) = %q, want %q", tt.in, got, tt.pascal)
+		}
+		if got := n.camel(tt.in); got != tt.camel {
+			t.Errorf("camel(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := n.snake(tt.in); got != tt.snake {
+			t.Errorf("snake(%q) = %q, want %q", tt.in, got, tt.snake)
+		}
+	}
+}
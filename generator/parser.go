@@ -0,0 +1,364 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// exportDirective 是 cgo 导出指令的前缀
+const exportDirective = "//export "
+
+// parsedPackage 是解析一个包得到的导出信息
+type parsedPackage struct {
+	Exports  []ExportedFunc // 导出的函数
+	Structs  []StructDef    // 通过 //gophp:struct 导出的结构体
+	Handles  []*HandleDef   // 通过 //gophp:handle 导出的句柄类型
+	Enums    []EnumDef      // 包内命名类型的导出常量（仅写入 API 清单）
+	Consts   []ConstDef     // 不属于枚举的导出常量（仅写入 API 清单）
+	Warnings []string       // 解析过程中跳过的声明及原因
+}
+
+// funcs 返回需要生成绑定的全部函数（普通导出函数以及句柄类型的构造函数、方法与释放函数）
+func (p *parsedPackage) funcs() []ExportedFunc {
+	funcs := append([]ExportedFunc{}, p.Exports...)
+	for _, h := range p.Handles {
+		funcs = append(funcs, h.funcs()...)
+	}
+	return funcs
+}
+
+// parsePackage 使用 go/parser 与 go/types 解析同一个包的源文件并提取导出的函数与结构体
+// jsonMode 为 true 时，签名中含有 map/结构体的导出 Go 函数也会通过 JSON 桥接导出
+func parsePackage(filenames []string, jsonMode bool) (*parsedPackage, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, filename := range filenames {
+		file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	info := typeCheck(fset, files)
+
+	pkg := &parsedPackage{}
+	pkg.Enums, pkg.Consts = collectConsts(fset, files, info)
+	var exports []ExportedFunc
+	var handles []*HandleDef
+	for _, file := range files {
+		structs, warnings := collectStructs(fset, file, info)
+		pkg.Structs = append(pkg.Structs, structs...)
+		pkg.Warnings = append(pkg.Warnings, warnings...)
+		fileHandles, warnings := collectHandles(fset, file, info)
+		handles = append(handles, fileHandles...)
+		pkg.Warnings = append(pkg.Warnings, warnings...)
+		exports = append(exports, fileExports(fset, file, info, jsonMode)...)
+	}
+	handles, warnings := attachHandleFuncs(fset, files, info, handles)
+	pkg.Warnings = append(pkg.Warnings, warnings...)
+
+	bindStructs(exports, pkg.Structs)
+	for _, h := range handles {
+		ctor := []ExportedFunc{h.Constructor}
+		bindStructs(ctor, pkg.Structs)
+		h.Constructor = ctor[0]
+		bindStructs(h.Methods, pkg.Structs)
+	}
+
+	// //gophp:export 函数与句柄方法中除 DTO 以外的 map/结构体无法通过适配函数传递
+	unsupported := func(exp ExportedFunc) bool {
+		p, ok := unboundComplexType(exp)
+		if ok {
+			pkg.Warnings = append(pkg.Warnings, fmt.Sprintf("%s: skipping %s: %s is neither a //gophp:struct type nor bridged through JSON", exp.Pos, exp.Name, p.Type))
+		}
+		return ok
+	}
+	// 元素不是数值、bool 或 string 的切片参数无法由 PHP 数组构造
+	unsupportedSlice := func(exp ExportedFunc) bool {
+		p, ok := unsupportedSliceParam(exp)
+		if ok {
+			pkg.Warnings = append(pkg.Warnings, fmt.Sprintf("%s: skipping %s: slice parameter %s cannot be built from a PHP array", exp.Pos, exp.Name, p.Type))
+		}
+		return ok
+	}
+	for _, exp := range exports {
+		if exp.Shim && unsupported(exp) || unsupportedSlice(exp) {
+			continue
+		}
+		pkg.Exports = append(pkg.Exports, exp)
+	}
+	for _, h := range handles {
+		if unsupported(h.Constructor) || unsupportedSlice(h.Constructor) {
+			continue
+		}
+		methods := h.Methods[:0]
+		for _, m := range h.Methods {
+			if !unsupported(m) && !unsupportedSlice(m) {
+				methods = append(methods, m)
+			}
+		}
+		h.Methods = methods
+		pkg.Handles = append(pkg.Handles, h)
+	}
+	return pkg, nil
+}
+
+// fileExports 提取单个文件中带 //export 指令的函数以及需要 JSON 桥接的函数
+func fileExports(fset *token.FileSet, file *ast.File, info *types.Info, jsonMode bool) []ExportedFunc {
+	var exports []ExportedFunc
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+
+		params := collectFields(fn.Type.Params, info, "arg")
+		results := collectFields(fn.Type.Results, info, "result")
+		directives := parseDirectives(fn.Doc)
+
+		exportName, ok := findExportName(fn.Doc)
+		bridged, shim := false, false
+		if !ok {
+			// map 与结构体无法直接 //export：DTO 使用 //gophp:export [Name] 通过适配函数导出，
+			// 其他复杂类型使用 //gophp:json [Name] 或服务级 JSON 模式桥接
+			exportedName, exported := directives["export"]
+			jsonName, marked := directives["json"]
+			candidate := ExportedFunc{Params: params, Results: results}
+			switch {
+			case exported:
+				exportName = fn.Name.Name
+				if exportedName != "" {
+					exportName = exportedName
+				}
+				shim = true
+			case marked:
+				exportName = fn.Name.Name
+				if jsonName != "" {
+					exportName = jsonName
+				}
+			case jsonMode && fn.Name.IsExported() && hasComplexSignature(candidate):
+				exportName = fn.Name.Name
+			default:
+				continue
+			}
+			bridged = !shim
+		}
+
+		exp := newExportedFunc(fset, fn, info, exportName)
+		exp.JSON = bridged
+		exp.Shim = shim
+		exports = append(exports, exp)
+	}
+	return exports
+}
+
+// newExportedFunc 根据函数声明构造 ExportedFunc
+func newExportedFunc(fset *token.FileSet, fn *ast.FuncDecl, info *types.Info, name string) ExportedFunc {
+	results := collectFields(fn.Type.Results, info, "result")
+	comment, doc := docText(fn.Doc)
+	return ExportedFunc{
+		Name:       name,
+		GoName:     fn.Name.Name,
+		Comment:    comment,
+		Doc:        doc,
+		Signature:  funcSignature(fn),
+		ReturnType: returnTypeOf(results),
+		Params:     collectFields(fn.Type.Params, info, "arg"),
+		Results:    results,
+		Pos:        fset.Position(fn.Pos()),
+		Directives: parseDirectives(fn.Doc),
+	}
+}
+
+// typeCheck 对文件进行类型检查
+// 类型错误（例如引用了 C.xxx 或同包其他文件中的声明）不会中断解析，
+// 无法解析的类型会回退为源码表达式
+func typeCheck(fset *token.FileSet, files []*ast.File) *types.Info {
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{
+		Importer:    importer.ForCompiler(fset, "source", nil),
+		FakeImportC: true,
+		Error:       func(error) {},
+	}
+	name := "main"
+	if len(files) > 0 {
+		name = files[0].Name.Name
+	}
+	conf.Check(name, fset, files, info)
+	return info
+}
+
+// findExportName 从文档注释中查找 //export 指令
+func findExportName(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, c := range doc.List {
+		if strings.HasPrefix(c.Text, exportDirective) {
+			fields := strings.Fields(strings.TrimPrefix(c.Text, exportDirective))
+			if len(fields) > 0 {
+				return fields[0], true
+			}
+		}
+	}
+	return "", false
+}
+
+// gophpDirective 是生成器自定义指令的前缀
+const gophpDirective = "//gophp:"
+
+// parseDirectives 解析文档注释中的 //gophp:key value 指令
+func parseDirectives(doc *ast.CommentGroup) map[string]string {
+	directives := make(map[string]string)
+	if doc == nil {
+		return directives
+	}
+	for _, c := range doc.List {
+		if !strings.HasPrefix(c.Text, gophpDirective) {
+			continue
+		}
+		body := strings.TrimPrefix(c.Text, gophpDirective)
+		key, value, _ := strings.Cut(body, " ")
+		directives[key] = strings.TrimSpace(value)
+	}
+	return directives
+}
+
+// isDirective 判断注释行是否为工具指令（//export、//go:xxx 等）
+func isDirective(text string) bool {
+	if strings.HasPrefix(text, exportDirective) {
+		return true
+	}
+	if !strings.HasPrefix(text, "//") || strings.HasPrefix(text, "// ") {
+		return false
+	}
+	// 与 go/ast 一致：//[a-z0-9]+:[a-z0-9] 形式视为指令
+	body := text[2:]
+	colon := strings.Index(body, ":")
+	if colon <= 0 || colon+1 >= len(body) {
+		return false
+	}
+	for _, r := range body[:colon] {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	next := body[colon+1]
+	return next >= 'a' && next <= 'z' || next >= '0' && next <= '9'
+}
+
+// docText 提取文档注释文本，返回单行形式与保留换行的完整形式
+func docText(doc *ast.CommentGroup) (string, string) {
+	if doc == nil {
+		return "", ""
+	}
+
+	var lines []string
+	for _, c := range doc.List {
+		if isDirective(c.Text) {
+			continue
+		}
+		text := c.Text
+		switch {
+		case strings.HasPrefix(text, "//"):
+			lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(text, "//"), " "))
+		case strings.HasPrefix(text, "/*"):
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+			for _, l := range strings.Split(text, "\n") {
+				lines = append(lines, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), "*")))
+			}
+		}
+	}
+
+	// 去掉首尾空行
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var words []string
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l != "" {
+			words = append(words, l)
+		}
+	}
+
+	return strings.Join(words, " "), strings.Join(lines, "\n")
+}
+
+// collectFields 将参数或返回值列表展开为 Param 切片
+// 匿名字段使用 prefix + 序号命名
+func collectFields(list *ast.FieldList, info *types.Info, prefix string) []Param {
+	if list == nil {
+		return nil
+	}
+
+	var params []Param
+	for _, field := range list.List {
+		goType := info.TypeOf(field.Type)
+		typeStr := types.ExprString(field.Type)
+		if goType != nil && goType != types.Typ[types.Invalid] {
+			typeStr = types.TypeString(goType, localQualifier)
+		} else {
+			goType = nil
+		}
+
+		if len(field.Names) == 0 {
+			params = append(params, Param{
+				Name:   fmt.Sprintf("%s%d", prefix, len(params)),
+				Type:   typeStr,
+				GoType: goType,
+			})
+			continue
+		}
+		for _, ident := range field.Names {
+			name := ident.Name
+			if name == "_" {
+				name = fmt.Sprintf("%s%d", prefix, len(params))
+			}
+			params = append(params, Param{
+				Name:   name,
+				Type:   typeStr,
+				GoType: goType,
+			})
+		}
+	}
+	return params
+}
+
+// localQualifier 对当前包内的类型不加包名前缀，其他包使用包名
+func localQualifier(pkg *types.Package) string {
+	if pkg.Name() == "main" {
+		return ""
+	}
+	return pkg.Name()
+}
+
+// returnTypeOf 根据返回值列表计算 ReturnType 字符串
+func returnTypeOf(results []Param) string {
+	if len(results) == 0 {
+		return "void"
+	}
+	names := make([]string, 0, len(results))
+	for _, r := range results {
+		names = append(names, r.Type)
+	}
+	return strings.Join(names, ", ")
+}
+
+// funcSignature 输出不含函数体与注释的规范化函数签名
+func funcSignature(fn *ast.FuncDecl) string {
+	return "func " + fn.Name.Name + strings.TrimPrefix(types.ExprString(fn.Type), "func")
+}
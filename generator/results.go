@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// 多返回值在 PHP 中的映射方式
+const (
+	ResultModeArray = "array" // 按位置返回 PHP 数组
+	ResultModeClass = "class" // 返回生成的结果类
+)
+
+// resultMode 确定函数多返回值的映射方式
+// 优先使用 //gophp:result 指令，否则使用全局默认值
+func resultMode(exp ExportedFunc, defaultMode string) string {
+	switch mode := exp.Directives["result"]; mode {
+	case ResultModeArray, ResultModeClass:
+		return mode
+	}
+	if defaultMode == ResultModeClass {
+		return ResultModeClass
+	}
+	return ResultModeArray
+}
+
+// hasMultiResults 判断函数是否返回多个值（不含末尾的 error）
+func hasMultiResults(exp ExportedFunc) bool {
+	return len(valueResults(exp)) > 1
+}
+
+// returnsError 判断函数的最后一个返回值是否为 error
+func returnsError(exp ExportedFunc) bool {
+	if len(exp.Results) == 0 {
+		return false
+	}
+	last := exp.Results[len(exp.Results)-1]
+	if last.GoType != nil {
+		return types.Identical(last.GoType, types.Universe.Lookup("error").Type())
+	}
+	return last.Type == "error"
+}
+
+// valueResults 返回 PHP 方法实际返回的值（去掉末尾的 error）
+func valueResults(exp ExportedFunc) []Param {
+	if returnsError(exp) {
+		return exp.Results[:len(exp.Results)-1]
+	}
+	return exp.Results
+}
+
+// resultClassName 返回结果类的类名（按类名策略由 <导出名>Result 转换）
+func resultClassName(exp ExportedFunc) string {
+	if exp.ResultClass != "" {
+		return exp.ResultClass
+	}
+	return exp.Name + "Result"
+}
+
+// cgoResultField 返回 cgo 生成的返回值结构体中第 i 个字段名
+func cgoResultField(i int) string {
+	return fmt.Sprintf("r%d", i)
+}
+
+// multiResultPHPType 返回多返回值方法的 PHP 类型提示与 PHPDoc 类型
+func multiResultPHPType(exp ExportedFunc, mode string) (string, string) {
+	if mode == ResultModeClass {
+		return resultClassName(exp), resultClassName(exp)
+	}
+	shape := make([]string, 0, len(exp.Results))
+	for i, r := range valueResults(exp) {
+		shape = append(shape, fmt.Sprintf("%d: %s", i, resultPHPDoc(exp, r)))
+	}
+	return "array", fmt.Sprintf("array{%s}", strings.Join(shape, ", "))
+}
+
+// phpResultValues 根据 cgo 返回值布局生成每个 Go 返回值对应的 PHP 表达式
+// field 返回第 i 个 cgo 字段的访问表达式
+func phpResultValues(exp ExportedFunc, layout cgoLayout, field func(int) string) []string {
+	values := valueResults(exp)
+	exprs := make([]string, 0, len(values))
+	for i, r := range values {
+		fields := layout.Values[i]
+		switch {
+		case isStructValue(exp, r):
+			// 适配层返回的 C 结构体：转换为 DTO 并释放其中的字符串
+			exprs = append(exprs, fmt.Sprintf("$this->fromC%s(%s)", r.Struct.Name, field(fields[0])))
+		case isJSONValue(exp, r):
+			// JSON 桥接的返回值：先复制并释放 JSON 字符串，再解码为 PHP 数组
+			exprs = append(exprs, fmt.Sprintf("json_decode($this->takeCString(%s, %s), true)", field(fields[0]), field(fields[1])))
+		case len(fields) == 2 && isCopyableSlice(r):
+			// 适配层返回的 C 数组：指针 + 元素个数
+			exprs = append(exprs, fmt.Sprintf("$this->takeCArray(%s, %s, '%s')", field(fields[0]), field(fields[1]), sliceElemCType(r)))
+		case len(fields) == 2:
+			// 适配层返回的 C 内存：指针 + 长度
+			exprs = append(exprs, fmt.Sprintf("$this->takeCString(%s, %s)", field(fields[0]), field(fields[1])))
+		case isCString(r):
+			exprs = append(exprs, fmt.Sprintf("$this->takeCString(%s)", field(fields[0])))
+		default:
+			exprs = append(exprs, field(fields[0]))
+		}
+	}
+	return exprs
+}
+
+// generateResultUnpack 生成将返回值组合为 PHP 数组或结果对象的表达式
+func generateResultUnpack(exp ExportedFunc, mode string, values []string) string {
+	if mode == ResultModeClass {
+		return fmt.Sprintf("new %s(%s)", resultClassName(exp), strings.Join(values, ", "))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(values, ", "))
+}
+
+// generateResultClass 生成多返回值对应的 PHP 结果类
+func generateResultClass(exp ExportedFunc) string {
+	var sb strings.Builder
+
+	sb.WriteString("/**\n")
+	sb.WriteString(fmt.Sprintf(" * Result of %s\n", exp.Name))
+	sb.WriteString(" */\n")
+	sb.WriteString(fmt.Sprintf("final class %s {\n", resultClassName(exp)))
+
+	results := valueResults(exp)
+	args := make([]string, 0, len(results))
+	for _, r := range results {
+		hint := resultPHPHint(exp, r)
+		sb.WriteString(fmt.Sprintf("    /** @var %s */\n", resultPHPDoc(exp, r)))
+		if hint != "" {
+			sb.WriteString(fmt.Sprintf("    public %s $%s;\n", hint, r.Name))
+			args = append(args, fmt.Sprintf("%s $%s", hint, r.Name))
+		} else {
+			sb.WriteString(fmt.Sprintf("    public $%s;\n", r.Name))
+			args = append(args, "$"+r.Name)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("    public function __construct(%s) {\n", strings.Join(args, ", ")))
+	for _, r := range results {
+		sb.WriteString(fmt.Sprintf("        $this->%s = $%s;\n", r.Name, r.Name))
+	}
+	sb.WriteString("    }\n")
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
@@ -0,0 +1,235 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PHP 运行时模式
+const (
+	RuntimeLibrary  = "library"  // 继承 Composer 包 wuwuseo/phpffi-go-library 中的 GoLibraryBase
+	RuntimeEmbedded = "embedded" // 自包含：内嵌 C 声明与库加载逻辑，仅依赖 ext-ffi
+)
+
+// generateEmbeddedRuntime 生成自包含服务类的开头部分：
+// 内嵌的 C 声明、按平台与架构查找共享库的逻辑以及 FFI 实例的创建
+func generateEmbeddedRuntime(pkg *parsedPackage, opts generateOptions) string {
+	var sb strings.Builder
+
+	if opts.BaseClass != "" {
+		sb.WriteString(fmt.Sprintf("class %s extends \\%s {\n\n", opts.ClassName, opts.BaseClass))
+	} else {
+		sb.WriteString(fmt.Sprintf("class %s {\n\n", opts.ClassName))
+	}
+	sb.WriteString("    /**\n")
+	sb.WriteString("     * 共享库导出符号的 C 声明（由生成器根据 Go 源码生成）\n")
+	sb.WriteString("     */\n")
+	sb.WriteString("    private const CDEF = <<<'CDEF'\n")
+	sb.WriteString(generateCDeclarations(pkg, opts))
+	sb.WriteString("CDEF;\n\n")
+
+	sb.WriteString("    /** @var \\FFI */\n")
+	sb.WriteString("    protected \\FFI $ffi;\n\n")
+	if opts.Preload {
+		sb.WriteString(generatePreloadMethods(opts))
+	} else {
+		sb.WriteString(`    /**
+     * 加载共享库
+     * @param string|null $libraryPath 共享库路径，默认按当前平台查找（见 libraryPath）
+     */
+    public function __construct(?string $libraryPath = null)
+    {
+        $this->ffi = \FFI::cdef(self::CDEF, $libraryPath ?? static::libraryPath());
+    }
+`)
+	}
+
+	sb.WriteString(fmt.Sprintf(`
+    /**
+     * 返回当前平台与架构对应的共享库路径（%s/%s-<os>-<arch>.<ext>）
+     * @return string
+     */
+    public static function libraryPath(): string
+    {
+        switch (PHP_OS_FAMILY) {
+            case 'Windows':
+                $os = 'windows';
+                $ext = 'dll';
+                break;
+            case 'Darwin':
+                $os = 'darwin';
+                $ext = 'dylib';
+                break;
+            case 'BSD':
+                $os = 'freebsd';
+                $ext = 'so';
+                break;
+            default:
+                $os = 'linux';
+                $ext = 'so';
+        }
+
+        $machine = strtolower(php_uname('m'));
+        $arches = [
+            'x86_64' => 'amd64',
+            'amd64' => 'amd64',
+            'aarch64' => 'arm64',
+            'arm64' => 'arm64',
+            'i386' => '386',
+            'i686' => '386',
+            'x86' => '386',
+            'armv7l' => 'arm',
+            'armv6l' => 'arm',
+        ];
+        $arch = $arches[$machine] ?? $machine;
+
+        $path = %s . $os . '-' . $arch . '.' . $ext;
+        if (!is_file($path)) {
+            throw new \RuntimeException("Go library not found for {$os}/{$arch}: {$path}");
+        }
+        return $path;
+    }
+`, libDirLabel(opts), opts.ServiceName, phpLibPathPrefix(opts, opts.ServiceName+"-")))
+
+	return sb.String()
+}
+
+// libDirRel 返回库目录相对 PHP 输出目录的路径（以 / 分隔），无法相对表示时返回 false
+func libDirRel(opts generateOptions) (string, bool) {
+	if opts.LibDir == "" {
+		return "lib", true
+	}
+	rel, err := filepath.Rel(opts.OutputDir, opts.LibDir)
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// libDirLabel 返回文档注释中展示的库目录
+func libDirLabel(opts generateOptions) string {
+	if rel, ok := libDirRel(opts); ok {
+		return rel
+	}
+	return filepath.ToSlash(opts.LibDir)
+}
+
+// phpLibPathPrefix 返回库目录中以 prefix 开头的文件路径的 PHP 表达式：
+// 能相对表示时基于 __DIR__，否则使用绝对路径
+func phpLibPathPrefix(opts generateOptions, prefix string) string {
+	if rel, ok := libDirRel(opts); ok {
+		return fmt.Sprintf("__DIR__ . '/%s'", phpEscape(path.Join(rel, prefix)))
+	}
+	return phpStringLiteral(path.Join(absPath(opts.LibDir), prefix))
+}
+
+// phpBaseDirExpr 返回 GoLibraryBase::getBaseDir 的表达式
+// GoLibraryBase 从 <基础目录>/lib 加载共享库，因此基础目录取库目录的上一级
+func phpBaseDirExpr(opts generateOptions) string {
+	if rel, ok := libDirRel(opts); ok {
+		switch parent := path.Dir(rel); parent {
+		case ".":
+			return "__DIR__"
+		case "..":
+			return "dirname(__DIR__)"
+		}
+		return fmt.Sprintf("__DIR__ . '/%s'", phpEscape(path.Dir(rel)))
+	}
+	return phpStringLiteral(filepath.ToSlash(filepath.Dir(absPath(opts.LibDir))))
+}
+
+// absPath 返回以 / 分隔的绝对路径，失败时原样返回
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	return filepath.ToSlash(p)
+}
+
+// phpEscape 转义 PHP 单引号字符串中的特殊字符
+func phpEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+// phpStringLiteral 返回 PHP 单引号字符串字面量
+func phpStringLiteral(s string) string {
+	return "'" + phpEscape(s) + "'"
+}
+
+// generatePreloadMethods 生成支持 opcache 预加载的构造函数：
+// 预加载时通过 FFI::load 注册 FFI_SCOPE，请求中通过 FFI::scope 复用，未预加载时（如 CLI）回退到 FFI::cdef
+func generatePreloadMethods(opts generateOptions) string {
+	return fmt.Sprintf(`    /** FFI::load 注册的作用域名 */
+    public const FFI_SCOPE = '%s';
+
+    /**
+     * 加载共享库：已预加载时使用 FFI::scope，否则回退到 FFI::cdef
+     * @param string|null $libraryPath 共享库路径，默认按当前平台查找（见 libraryPath）
+     */
+    public function __construct(?string $libraryPath = null)
+    {
+        if ($libraryPath === null) {
+            try {
+                $this->ffi = \FFI::scope(self::FFI_SCOPE);
+                return;
+            } catch (\FFI\Exception $e) {
+                // 未预加载，回退到 FFI::cdef
+            }
+        }
+        $this->ffi = \FFI::cdef(self::CDEF, $libraryPath ?? static::libraryPath());
+    }
+
+    /**
+     * 在 opcache.preload 脚本中调用，注册带 FFI_SCOPE 的声明
+     * 需要 php.ini 中设置 ffi.enable=preload（或 true）
+     * @param string|null $libraryPath 共享库路径，默认按当前平台查找（见 libraryPath）
+     * @return void
+     */
+    public static function preload(?string $libraryPath = null): void
+    {
+        $header = sprintf(
+            "#define FFI_SCOPE \"%%s\"\n#define FFI_LIB \"%%s\"\n%%s",
+            self::FFI_SCOPE,
+            addcslashes($libraryPath ?? static::libraryPath(), '"\\'),
+            self::CDEF
+        );
+        // FFI::load 只接受文件，这里写入临时头文件
+        $file = tempnam(sys_get_temp_dir(), 'gophp');
+        file_put_contents($file, $header);
+        try {
+            \FFI::load($file);
+        } finally {
+            unlink($file);
+        }
+    }
+`, opts.ServiceName)
+}
+
+// preloadFileName 返回预加载脚本的文件名（<服务类文件名>.preload.php）
+func preloadFileName(opts generateOptions) string {
+	return strings.TrimSuffix(filepath.Base(opts.PHPFile), ".php") + ".preload.php"
+}
+
+// generatePreloadScript 生成 opcache.preload 脚本
+func generatePreloadScript(opts generateOptions) (string, error) {
+	script := fmt.Sprintf(`<?php
+/**
+ * opcache preload script
+ * Auto-generated by Go-PHP FFI Code Generator
+ *
+ * php.ini:
+ *   opcache.preload=/path/to/dist/%s
+ *   ffi.enable=preload
+ */
+
+require_once __DIR__ . '/%s';
+
+\%s\%s::preload();
+`, preloadFileName(opts), phpEscape(filepath.Base(opts.PHPFile)), opts.Namespace, opts.ClassName)
+
+	outputFile := filepath.Join(opts.OutputDir, preloadFileName(opts))
+	return outputFile, os.WriteFile(outputFile, []byte(script), 0644)
+}
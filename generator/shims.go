@@ -0,0 +1,364 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// shimFileSuffix 是生成的 Go 适配层文件后缀
+// 解析源码时会跳过带有该后缀的文件
+const shimFileSuffix = "_gophp.go"
+
+// ShimFileName 返回服务对应的 Go 适配层文件名（<snake_case 服务名>_gophp.go）
+func ShimFileName(service string) string {
+	return toSnakeCase(service) + shimFileSuffix
+}
+
+// shimHeader 是生成的 Go 适配层文件的首行，删除旧文件前用它确认文件由本工具生成
+const shimHeader = "// Code generated by gophpffi. DO NOT EDIT.\n\n"
+
+// legacyShimFileName 返回引入缩写识别之前的适配层文件名（每个大写字母前都插入下划线，例如 h_t_t_p_x_gophp.go）
+func legacyShimFileName(service string) string {
+	var sb strings.Builder
+	for i, r := range service {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			sb.WriteRune('_')
+		}
+		sb.WriteRune(r)
+	}
+	return strings.ToLower(sb.String()) + shimFileSuffix
+}
+
+// removeLegacyShim 删除旧命名规则生成的适配层文件，返回被删除的文件路径
+// 旧文件与新文件导出相同的辅助函数，留在包中会导致编译失败；不是本工具生成的文件不会被删除
+func removeLegacyShim(opts generateOptions) (string, error) {
+	legacy := legacyShimFileName(opts.ServiceName)
+	if legacy == ShimFileName(opts.ServiceName) {
+		return "", nil
+	}
+	path := filepath.Join(opts.SourceDir, legacy)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(string(data), shimHeader) {
+		return "", nil
+	}
+	return path, os.Remove(path)
+}
+
+// needsShim 判断导出函数是否需要生成 cgo 适配函数
+// 返回 error、string 或 []byte 的函数、使用 DTO 的函数以及句柄方法都通过适配函数导出
+func needsShim(exp ExportedFunc) bool {
+	if returnsError(exp) || exp.JSON || exp.Shim || usesStructs(exp) || handleOf(exp) != nil {
+		return true
+	}
+	for _, r := range valueResults(exp) {
+		if isOwnedResult(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// cgoLayout 描述 PHP 端看到的 cgo 返回值布局
+type cgoLayout struct {
+	Values   [][]int // 每个 Go 返回值对应的 cgo 字段下标（string/[]byte 为指针与长度两个字段）
+	ErrField int     // error 对应的字段下标，无 error 时为 -1
+	Total    int     // cgo 返回值字段总数，大于 1 时为 struct <Name>_return
+}
+
+// layoutOf 计算导出函数（或其适配函数）的 cgo 返回值布局
+func layoutOf(exp ExportedFunc) cgoLayout {
+	if exp.JSON {
+		return layoutOf(jsonBridge(exp))
+	}
+	layout := cgoLayout{ErrField: -1}
+	shim := needsShim(exp)
+	for _, r := range valueResults(exp) {
+		if shim && isOwnedResult(r) {
+			layout.Values = append(layout.Values, []int{layout.Total, layout.Total + 1})
+			layout.Total += 2
+			continue
+		}
+		layout.Values = append(layout.Values, []int{layout.Total})
+		layout.Total++
+	}
+	if returnsError(exp) {
+		layout.ErrField = layout.Total
+		layout.Total++
+	}
+	return layout
+}
+
+// shimName 返回适配函数的导出名
+func shimName(exp ExportedFunc, service string) string {
+	return toPascalCase(service) + "_" + exp.Name
+}
+
+// freeFuncName 返回用于释放 C 内存的导出函数名
+func freeFuncName(service string) string {
+	return toPascalCase(service) + "Free"
+}
+
+// ffiSymbol 返回 PHP 方法实际调用的 FFI 符号
+func ffiSymbol(exp ExportedFunc, service string) string {
+	if needsShim(exp) {
+		return shimName(exp, service)
+	}
+	return exp.Name
+}
+
+// generateGoShims 在源码目录中生成 Go 适配层文件
+// 适配层导出 cgo 友好的包装函数以及 <Service>Free，随共享库一起编译
+func generateGoShims(pkg *parsedPackage, opts generateOptions) (string, error) {
+	exports := pkg.funcs()
+	imports := map[string]bool{"unsafe": true}
+	var body strings.Builder
+
+	freeName := freeFuncName(opts.ServiceName)
+	body.WriteString(fmt.Sprintf("// %s 释放由本服务分配的 C 内存\n", freeName))
+	body.WriteString("//\n")
+	body.WriteString(fmt.Sprintf("//export %s\n", freeName))
+	body.WriteString(fmt.Sprintf("func %s(p unsafe.Pointer) {\n\tC.free(p)\n}\n", freeName))
+
+	if hasSliceResults(exports) {
+		body.WriteString("\n")
+		body.WriteString(copySliceHelper)
+	}
+	if hasStringSliceResults(exports) {
+		body.WriteString("\n")
+		body.WriteString(copyStringsHelper)
+	}
+
+	if len(pkg.Structs) > 0 {
+		body.WriteString(generateStructConverters(pkg.Structs, opts.ServiceName, imports))
+	}
+
+	for _, exp := range exports {
+		if !needsShim(exp) {
+			continue
+		}
+		body.WriteString("\n")
+		if exp.JSON {
+			body.WriteString(generateJSONBridge(exp, imports))
+			body.WriteString("\n")
+			exp = jsonBridge(exp)
+		}
+		body.WriteString(generateShim(exp, opts.ServiceName, imports))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(shimHeader)
+	sb.WriteString(fmt.Sprintf("package %s\n\n", opts.PackageName))
+	sb.WriteString("/*\n#include <stdlib.h>\n")
+	if len(pkg.Structs) > 0 {
+		// DTO 对应的 C 结构体定义会随前导注释一起写入导出的头文件
+		sb.WriteString("#include <stddef.h>\n#include <stdint.h>\n\n")
+		sb.WriteString(generateStructCTypes(pkg.Structs, opts.ServiceName))
+	}
+	sb.WriteString("*/\nimport \"C\"\n\n")
+
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	sb.WriteString("import (\n")
+	for _, path := range paths {
+		sb.WriteString(fmt.Sprintf("\t%q\n", path))
+	}
+	sb.WriteString(")\n\n")
+	sb.WriteString(body.String())
+
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return "", fmt.Errorf("format shims: %w", err)
+	}
+
+	outputFile := filepath.Join(opts.SourceDir, ShimFileName(opts.ServiceName))
+	return outputFile, os.WriteFile(outputFile, src, 0644)
+}
+
+// copySliceHelper 将切片内容复制到 C 内存的泛型辅助函数
+const copySliceHelper = `// gophpCopySlice 将切片元素复制到 C 分配的内存，空切片返回 nil
+func gophpCopySlice[T any](s []T) unsafe.Pointer {
+	if len(s) == 0 {
+		return nil
+	}
+	var zero T
+	p := C.malloc(C.size_t(len(s)) * C.size_t(unsafe.Sizeof(zero)))
+	copy(unsafe.Slice((*T)(p), len(s)), s)
+	return p
+}
+`
+
+// copyStringsHelper 将字符串切片复制到 C 内存的辅助函数
+// 数组元素与 cgo 头文件中的 GoString 布局一致，但 p 指向 C.CString 分配的内存，
+// PHP 读取每个元素后调用 <Service>Free 释放，最后释放数组本身
+const copyStringsHelper = `// gophpCopyStrings 将字符串切片复制为 C 分配的 GoString 数组，空切片返回 nil
+func gophpCopyStrings(s []string) unsafe.Pointer {
+	if len(s) == 0 {
+		return nil
+	}
+	type cString struct {
+		p *C.char
+		n int
+	}
+	p := C.malloc(C.size_t(len(s)) * C.size_t(unsafe.Sizeof(cString{})))
+	items := unsafe.Slice((*cString)(p), len(s))
+	for i, v := range s {
+		items[i] = cString{p: C.CString(v), n: len(v)}
+	}
+	return p
+}
+`
+
+// generateShim 为导出函数生成 cgo 适配函数
+// string/[]byte 与切片返回值被复制到 C 内存并附带长度返回，DTO 与 C 结构体相互转换，
+// error 被转换为 C 字符串（nil 时为 NULL），
+// PHP 读取后调用 <Service>Free 释放
+func generateShim(exp ExportedFunc, service string, imports map[string]bool) string {
+	var sb strings.Builder
+	name := shimName(exp, service)
+	values := valueResults(exp)
+
+	// 避免参数名与适配函数内部变量冲突
+	reserved := map[string]bool{"err": true, "C": true, "unsafe": true, "cgo": true, "handle": true}
+	for i := range values {
+		reserved[fmt.Sprintf("r%d", i)] = true
+	}
+
+	var params, args []string
+	if exp.Receiver != nil {
+		imports["runtime/cgo"] = true
+		params = append(params, "handle uintptr")
+	}
+	for _, p := range exp.Params {
+		paramName := p.Name
+		for reserved[paramName] {
+			paramName += "_"
+		}
+		if isStructValue(exp, p) {
+			params = append(params, fmt.Sprintf("%s C.%s", paramName, structCName(p.Struct, service)))
+			args = append(args, fmt.Sprintf("gophpTo%s(%s)", p.Struct.Name, paramName))
+			continue
+		}
+		params = append(params, fmt.Sprintf("%s %s", paramName, goTypeExpr(p, imports)))
+		args = append(args, paramName)
+	}
+
+	// 返回值类型以及成功/失败时的返回表达式
+	var resultTypes, resultVars, okValues, errValues []string
+	for i, r := range values {
+		v := fmt.Sprintf("r%d", i)
+		resultVars = append(resultVars, v)
+		switch {
+		case r.Handle != nil:
+			imports["runtime/cgo"] = true
+			resultTypes = append(resultTypes, "uintptr")
+			okValues = append(okValues, fmt.Sprintf("uintptr(cgo.NewHandle(%s))", v))
+			errValues = append(errValues, "0")
+		case isStructValue(exp, r):
+			cName := "C." + structCName(r.Struct, service)
+			resultTypes = append(resultTypes, cName)
+			okValues = append(okValues, fmt.Sprintf("gophpFrom%s(%s)", r.Struct.Name, v))
+			errValues = append(errValues, cName+"{}")
+		case isGoString(r):
+			resultTypes = append(resultTypes, "*C.char", "C.size_t")
+			okValues = append(okValues, fmt.Sprintf("C.CString(string(%s))", v), fmt.Sprintf("C.size_t(len(%s))", v))
+			errValues = append(errValues, "nil", "0")
+		case isGoBytes(r):
+			resultTypes = append(resultTypes, "*C.char", "C.size_t")
+			okValues = append(okValues, fmt.Sprintf("(*C.char)(C.CBytes(%s))", v), fmt.Sprintf("C.size_t(len(%s))", v))
+			errValues = append(errValues, "nil", "0")
+		case isCopyableSlice(r):
+			copyFunc := "gophpCopySlice"
+			if sliceElemCType(r) == "GoString" {
+				copyFunc = "gophpCopyStrings"
+			}
+			resultTypes = append(resultTypes, "unsafe.Pointer", "C.size_t")
+			okValues = append(okValues, fmt.Sprintf("%s(%s)", copyFunc, v), fmt.Sprintf("C.size_t(len(%s))", v))
+			errValues = append(errValues, "nil", "0")
+		default:
+			resultTypes = append(resultTypes, goTypeExpr(r, imports))
+			okValues = append(okValues, v)
+			errValues = append(errValues, v)
+		}
+	}
+	if returnsError(exp) {
+		resultTypes = append(resultTypes, "*C.char")
+		okValues = append(okValues, "nil")
+		errValues = append(errValues, "C.CString(err.Error())")
+		resultVars = append(resultVars, "err")
+	}
+
+	results := strings.Join(resultTypes, ", ")
+	if len(resultTypes) > 1 {
+		results = "(" + results + ")"
+	}
+
+	switch {
+	case exp.Receiver != nil && exp.GoName == "":
+		sb.WriteString(fmt.Sprintf("// %s 释放 %s 句柄\n", name, exp.Receiver.Name))
+	case exp.Receiver != nil:
+		sb.WriteString(fmt.Sprintf("// %s 是 %s.%s 的适配函数\n", name, exp.Receiver.Name, exp.GoName))
+	default:
+		sb.WriteString(fmt.Sprintf("// %s 是 %s 的适配函数\n", name, exp.GoName))
+	}
+	sb.WriteString("//\n")
+	sb.WriteString(fmt.Sprintf("//export %s\n", name))
+	sb.WriteString(fmt.Sprintf("func %s(%s) %s {\n", name, strings.Join(params, ", "), results))
+
+	target := exp.GoName
+	if exp.Receiver != nil {
+		target = fmt.Sprintf("cgo.Handle(handle).Value().(*%s).%s", exp.Receiver.Name, exp.GoName)
+	}
+	if exp.Release && exp.GoName != "" {
+		// 先调用 Close，再删除句柄
+		sb.WriteString("\tdefer cgo.Handle(handle).Delete()\n")
+	}
+	call := fmt.Sprintf("%s(%s)", target, strings.Join(args, ", "))
+	switch {
+	case exp.Release && exp.GoName == "":
+		// 没有 Close 方法的类型只需删除句柄
+		sb.WriteString("\tcgo.Handle(handle).Delete()\n")
+	case len(resultVars) == 0:
+		sb.WriteString(fmt.Sprintf("\t%s\n", call))
+	default:
+		sb.WriteString(fmt.Sprintf("\t%s := %s\n", strings.Join(resultVars, ", "), call))
+	}
+	if returnsError(exp) {
+		sb.WriteString("\tif err != nil {\n")
+		sb.WriteString(fmt.Sprintf("\t\treturn %s\n", strings.Join(errValues, ", ")))
+		sb.WriteString("\t}\n")
+	}
+	if len(okValues) > 0 {
+		sb.WriteString(fmt.Sprintf("\treturn %s\n", strings.Join(okValues, ", ")))
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// goTypeExpr 返回在适配层文件中可用的 Go 类型表达式，并记录所需的导入
+func goTypeExpr(p Param, imports map[string]bool) string {
+	if p.GoType == nil {
+		return p.Type
+	}
+	return types.TypeString(p.GoType, func(pkg *types.Package) string {
+		if pkg.Name() == "main" {
+			return ""
+		}
+		imports[pkg.Path()] = true
+		return pkg.Name()
+	})
+}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sourcePackage 描述待解析的 Go 源码（单个文件或整个包）
+type sourcePackage struct {
+	Dir         string   // 源码所在目录
+	Name        string   // 默认服务名（文件名或目录名）
+	PackageName string   // Go 包名
+	ImportPath  string   // 导入路径（单文件模式为 command-line-arguments，无法确定时为源码目录）
+	Files       []string // 参与解析的源文件（绝对路径）
+}
+
+// loadSource 解析命令行传入的源码位置
+// 支持三种形式：单个 .go 文件、包目录、导入路径
+// 包模式下按 go/build 的规则（GOOS/GOARCH、//go:build 约束及 tags）筛选文件
+func loadSource(source string, tags []string) (*sourcePackage, error) {
+	if strings.HasSuffix(source, ".go") {
+		absPath, err := filepath.Abs(source)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), absPath, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return nil, err
+		}
+		return &sourcePackage{
+			Dir:         filepath.Dir(absPath),
+			Name:        strings.TrimSuffix(filepath.Base(absPath), ".go"),
+			PackageName: file.Name.Name,
+			ImportPath:  "command-line-arguments",
+			Files:       []string{absPath},
+		}, nil
+	}
+
+	ctxt := build.Default
+	ctxt.BuildTags = append(ctxt.BuildTags, tags...)
+	// c-shared 构建始终需要 cgo，这里强制开启以免 import "C" 的文件被忽略
+	ctxt.CgoEnabled = true
+
+	var pkg *build.Package
+	var err error
+	if info, statErr := os.Stat(source); statErr == nil && info.IsDir() {
+		absDir, absErr := filepath.Abs(source)
+		if absErr != nil {
+			return nil, absErr
+		}
+		pkg, err = ctxt.ImportDir(absDir, 0)
+	} else {
+		cwd, wdErr := os.Getwd()
+		if wdErr != nil {
+			return nil, wdErr
+		}
+		pkg, err = ctxt.Import(source, cwd, 0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load package %s: %w", source, err)
+	}
+
+	var files []string
+	for _, name := range append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...) {
+		// 跳过生成器自身输出的适配层文件
+		if strings.HasSuffix(name, shimFileSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(pkg.Dir, name))
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", pkg.Dir)
+	}
+
+	// 模块模式下 go/build 无法得到导入路径，根据 go.mod 推算
+	importPath := pkg.ImportPath
+	if importPath == "" || importPath == "." {
+		importPath = moduleImportPath(pkg.Dir)
+	}
+	if importPath == "" {
+		importPath = pkg.Dir
+	}
+	return &sourcePackage{
+		Dir:         pkg.Dir,
+		Name:        filepath.Base(pkg.Dir),
+		PackageName: pkg.Name,
+		ImportPath:  importPath,
+		Files:       files,
+	}, nil
+}
+
+// moduleImportPath 根据所在模块 go.mod 中的 module 声明推算目录的导入路径，不在模块中时返回空字符串
+func moduleImportPath(dir string) string {
+	for modDir := dir; ; {
+		data, err := os.ReadFile(filepath.Join(modDir, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+					rel, err := filepath.Rel(modDir, dir)
+					if err != nil {
+						return ""
+					}
+					return path.Join(strings.Trim(fields[1], `"`), filepath.ToSlash(rel))
+				}
+			}
+			return ""
+		}
+		parent := filepath.Dir(modDir)
+		if parent == modDir {
+			return ""
+		}
+		modDir = parent
+	}
+}
@@ -0,0 +1,387 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// StructDef 表示一个通过 //gophp:struct 指令导出为 PHP DTO 的 Go 结构体
+type StructDef struct {
+	Name    string         // Go 类型名
+	PHPName string         // PHP 类名（按类名策略由 Name 转换）
+	Comment string         // 单行化的文档注释
+	Fields  []StructField  // 导出到 PHP 的字段
+	Pos     token.Position // 类型声明在源码中的位置
+	Named   *types.Named   // 类型检查得到的命名类型
+}
+
+// StructField 表示 DTO 中的一个字段
+type StructField struct {
+	GoName  string // Go 字段名，同时作为 C 结构体字段名
+	PHPName string // PHP 属性名（由 php:"name" 标签控制）
+	Kind    types.BasicKind
+	GoType  types.Type
+}
+
+// structCFieldTypes 将结构体字段的基础类型映射为 C 类型
+// int/uint 与 Go 的指针宽度一致，对应 intptr_t/uintptr_t
+var structCFieldTypes = map[types.BasicKind]string{
+	types.Int:     "intptr_t",
+	types.Int8:    "int8_t",
+	types.Int16:   "int16_t",
+	types.Int32:   "int32_t",
+	types.Int64:   "int64_t",
+	types.Uint:    "uintptr_t",
+	types.Uint8:   "uint8_t",
+	types.Uint16:  "uint16_t",
+	types.Uint32:  "uint32_t",
+	types.Uint64:  "uint64_t",
+	types.Uintptr: "uintptr_t",
+	types.Float32: "float",
+	types.Float64: "double",
+	types.Bool:    "_Bool",
+	types.String:  "gophp_string",
+}
+
+// cStringTypedef 是 DTO 字符串字段使用的 C 类型
+const cStringTypedef = "typedef struct { char *p; size_t n; } gophp_string;"
+
+// collectStructs 提取带有 //gophp:struct 指令的结构体类型
+// 含有不支持字段类型的结构体会被跳过并产生警告
+func collectStructs(fset *token.FileSet, file *ast.File, info *types.Info) ([]StructDef, []string) {
+	var structs []StructDef
+	var warnings []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if _, ok := parseDirectives(doc)["struct"]; !ok {
+				continue
+			}
+
+			pos := fset.Position(ts.Pos())
+			obj, _ := info.Defs[ts.Name].(*types.TypeName)
+			if obj == nil {
+				warnings = append(warnings, fmt.Sprintf("%s: cannot resolve type %s", pos, ts.Name.Name))
+				continue
+			}
+			named, _ := obj.Type().(*types.Named)
+			st, ok := obj.Type().Underlying().(*types.Struct)
+			if named == nil || !ok {
+				warnings = append(warnings, fmt.Sprintf("%s: //gophp:struct requires a struct type, %s is not", pos, ts.Name.Name))
+				continue
+			}
+
+			fields, err := structFields(st)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: skipping %s: %v", pos, ts.Name.Name, err))
+				continue
+			}
+
+			comment, _ := docText(doc)
+			structs = append(structs, StructDef{
+				Name:    ts.Name.Name,
+				Comment: comment,
+				Fields:  fields,
+				Pos:     pos,
+				Named:   named,
+			})
+		}
+	}
+	return structs, warnings
+}
+
+// structFields 提取结构体中导出到 PHP 的字段
+func structFields(st *types.Struct) ([]StructField, error) {
+	var fields []StructField
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		phpName, ok := reflect.StructTag(st.Tag(i)).Lookup("php")
+		if phpName == "-" {
+			continue
+		}
+		if !ok || phpName == "" {
+			phpName = lowerCamel(field.Name())
+		}
+		basic, isBasic := field.Type().Underlying().(*types.Basic)
+		if !isBasic {
+			return nil, fmt.Errorf("field %s has unsupported type %s", field.Name(), field.Type())
+		}
+		if _, supported := structCFieldTypes[basic.Kind()]; !supported {
+			return nil, fmt.Errorf("field %s has unsupported type %s", field.Name(), field.Type())
+		}
+		fields = append(fields, StructField{
+			GoName:  field.Name(),
+			PHPName: phpName,
+			Kind:    basic.Kind(),
+			GoType:  field.Type(),
+		})
+	}
+	return fields, nil
+}
+
+// bindStructs 将参数与返回值关联到对应的 DTO 定义
+func bindStructs(exports []ExportedFunc, structs []StructDef) {
+	lookup := func(t types.Type) *StructDef {
+		if t == nil {
+			return nil
+		}
+		for i := range structs {
+			if types.Identical(t, structs[i].Named) {
+				return &structs[i]
+			}
+		}
+		return nil
+	}
+	for i := range exports {
+		for j := range exports[i].Params {
+			exports[i].Params[j].Struct = lookup(exports[i].Params[j].GoType)
+		}
+		for j := range exports[i].Results {
+			exports[i].Results[j].Struct = lookup(exports[i].Results[j].GoType)
+		}
+	}
+}
+
+// isStructValue 判断参数或返回值是否以 C 结构体形式传递的 DTO
+// JSON 桥接函数中的结构体仍然使用 JSON
+func isStructValue(exp ExportedFunc, p Param) bool {
+	return p.Struct != nil && !exp.JSON
+}
+
+// usesStructs 判断函数签名中是否使用了 DTO
+func usesStructs(exp ExportedFunc) bool {
+	for _, p := range exp.Params {
+		if isStructValue(exp, p) {
+			return true
+		}
+	}
+	for _, r := range valueResults(exp) {
+		if isStructValue(exp, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// structCName 返回 DTO 对应的 C 结构体类型名
+func structCName(def *StructDef, service string) string {
+	return fmt.Sprintf("gophp_%s_%s", toPascalCase(service), def.Name)
+}
+
+// structFieldPHPType 返回字段的 PHP 类型
+func structFieldPHPType(f StructField) string {
+	switch {
+	case f.Kind == types.Bool:
+		return "bool"
+	case f.Kind == types.String:
+		return "string"
+	case f.Kind == types.Float32 || f.Kind == types.Float64:
+		return "float"
+	}
+	return "int"
+}
+
+// structFieldPHPDefault 返回字段的 PHP 默认值
+func structFieldPHPDefault(f StructField) string {
+	switch structFieldPHPType(f) {
+	case "bool":
+		return "false"
+	case "string":
+		return "''"
+	case "float":
+		return "0.0"
+	}
+	return "0"
+}
+
+// generateStructCTypes 生成 DTO 对应的 C 结构体定义（写入适配层的 cgo 前导注释）
+func generateStructCTypes(structs []StructDef, service string) string {
+	var sb strings.Builder
+	sb.WriteString(cStringTypedef + "\n")
+	for i := range structs {
+		def := &structs[i]
+		sb.WriteString("\ntypedef struct {\n")
+		for _, f := range def.Fields {
+			sb.WriteString(fmt.Sprintf("\t%s %s;\n", structCFieldTypes[f.Kind], f.GoName))
+		}
+		sb.WriteString(fmt.Sprintf("} %s;\n", structCName(def, service)))
+	}
+	return sb.String()
+}
+
+// generateStructConverters 生成 Go 结构体与 C 结构体之间的转换函数
+// C -> Go 时字符串被复制到 Go 内存；Go -> C 时字符串被复制到 C 内存，由 PHP 读取后释放
+func generateStructConverters(structs []StructDef, service string, imports map[string]bool) string {
+	var sb strings.Builder
+	for i := range structs {
+		def := &structs[i]
+		cName := structCName(def, service)
+		qualify := func(t types.Type) string {
+			return goTypeExpr(Param{GoType: t}, imports)
+		}
+
+		sb.WriteString(fmt.Sprintf("\n// gophpTo%s 将 C 结构体转换为 %s\n", def.Name, def.Name))
+		sb.WriteString(fmt.Sprintf("func gophpTo%s(c C.%s) %s {\n", def.Name, cName, def.Name))
+		sb.WriteString(fmt.Sprintf("\treturn %s{\n", def.Name))
+		for _, f := range def.Fields {
+			if f.Kind == types.String {
+				sb.WriteString(fmt.Sprintf("\t\t%s: %s(C.GoStringN(c.%s.p, C.int(c.%s.n))),\n", f.GoName, qualify(f.GoType), f.GoName, f.GoName))
+			} else {
+				sb.WriteString(fmt.Sprintf("\t\t%s: %s(c.%s),\n", f.GoName, qualify(f.GoType), f.GoName))
+			}
+		}
+		sb.WriteString("\t}\n}\n")
+
+		sb.WriteString(fmt.Sprintf("\n// gophpFrom%s 将 %s 转换为 C 结构体\n", def.Name, def.Name))
+		sb.WriteString(fmt.Sprintf("func gophpFrom%s(v %s) C.%s {\n", def.Name, def.Name, cName))
+		sb.WriteString(fmt.Sprintf("\tvar c C.%s\n", cName))
+		for _, f := range def.Fields {
+			if f.Kind == types.String {
+				sb.WriteString(fmt.Sprintf("\tc.%s = C.gophp_string{p: C.CString(string(v.%s)), n: C.size_t(len(v.%s))}\n", f.GoName, f.GoName, f.GoName))
+			} else {
+				sb.WriteString(fmt.Sprintf("\tc.%s = C.%s(v.%s)\n", f.GoName, structCFieldTypes[f.Kind], f.GoName))
+			}
+		}
+		sb.WriteString("\treturn c\n}\n")
+	}
+	return sb.String()
+}
+
+// generateDTOClass 生成 DTO 对应的 PHP 类
+func generateDTOClass(def StructDef) string {
+	var sb strings.Builder
+
+	sb.WriteString("/**\n")
+	if def.Comment != "" {
+		sb.WriteString(fmt.Sprintf(" * %s\n", def.Comment))
+	} else {
+		sb.WriteString(fmt.Sprintf(" * DTO for Go struct %s\n", def.Name))
+	}
+	sb.WriteString(" */\n")
+	sb.WriteString(fmt.Sprintf("final class %s {\n", def.PHPName))
+
+	for _, f := range def.Fields {
+		phpType := structFieldPHPType(f)
+		sb.WriteString(fmt.Sprintf("    /** @var %s */\n", phpType))
+		sb.WriteString(fmt.Sprintf("    public %s $%s = %s;\n\n", phpType, f.PHPName, structFieldPHPDefault(f)))
+	}
+
+	sb.WriteString("    /**\n")
+	sb.WriteString("     * 从关联数组创建实例，缺失的键保留默认值\n")
+	sb.WriteString("     * @param array<string, mixed> $data\n")
+	sb.WriteString("     * @return self\n")
+	sb.WriteString("     */\n")
+	sb.WriteString("    public static function fromArray(array $data): self {\n")
+	sb.WriteString("        $dto = new self();\n")
+	for _, f := range def.Fields {
+		sb.WriteString(fmt.Sprintf("        if (array_key_exists('%s', $data)) {\n", f.PHPName))
+		sb.WriteString(fmt.Sprintf("            $dto->%s = (%s)$data['%s'];\n", f.PHPName, structFieldPHPType(f), f.PHPName))
+		sb.WriteString("        }\n")
+	}
+	sb.WriteString("        return $dto;\n")
+	sb.WriteString("    }\n\n")
+
+	sb.WriteString("    /**\n")
+	sb.WriteString("     * 转换为关联数组\n")
+	sb.WriteString("     * @return array<string, mixed>\n")
+	sb.WriteString("     */\n")
+	sb.WriteString("    public function toArray(): array {\n")
+	sb.WriteString("        return [\n")
+	for _, f := range def.Fields {
+		sb.WriteString(fmt.Sprintf("            '%s' => $this->%s,\n", f.PHPName, f.PHPName))
+	}
+	sb.WriteString("        ];\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// generateStructHelpers 生成 DTO 与 C 结构体之间相互转换的私有方法
+func generateStructHelpers(structs []StructDef, service string) string {
+	var sb strings.Builder
+	for i := range structs {
+		def := &structs[i]
+		cName := structCName(def, service)
+
+		sb.WriteString("\n    /**\n")
+		sb.WriteString(fmt.Sprintf("     * 将 %s 转换为 C 结构体 %s\n", def.PHPName, cName))
+		sb.WriteString(fmt.Sprintf("     * @param %s $value\n", def.PHPName))
+		sb.WriteString("     * @param array $buffers\n")
+		sb.WriteString("     * @return \\FFI\\CData\n")
+		sb.WriteString("     */\n")
+		sb.WriteString(fmt.Sprintf("    private function toC%s(%s $value, array &$buffers): \\FFI\\CData {\n", def.Name, def.PHPName))
+		sb.WriteString(fmt.Sprintf("        $data = $this->ffi->new('%s');\n", cName))
+		for _, f := range def.Fields {
+			if f.Kind == types.String {
+				sb.WriteString(fmt.Sprintf("        $data->%s->p = $this->toCBuffer($value->%s, $buffers);\n", f.GoName, f.PHPName))
+				sb.WriteString(fmt.Sprintf("        $data->%s->n = strlen($value->%s);\n", f.GoName, f.PHPName))
+			} else {
+				sb.WriteString(fmt.Sprintf("        $data->%s = $value->%s;\n", f.GoName, f.PHPName))
+			}
+		}
+		sb.WriteString("        return $data;\n")
+		sb.WriteString("    }\n\n")
+
+		sb.WriteString("    /**\n")
+		sb.WriteString(fmt.Sprintf("     * 将 C 结构体 %s 转换为 %s，并释放其中的字符串\n", cName, def.PHPName))
+		sb.WriteString("     * @param \\FFI\\CData $data\n")
+		sb.WriteString(fmt.Sprintf("     * @return %s\n", def.PHPName))
+		sb.WriteString("     */\n")
+		sb.WriteString(fmt.Sprintf("    private function fromC%s(\\FFI\\CData $data): %s {\n", def.Name, def.PHPName))
+		sb.WriteString(fmt.Sprintf("        $dto = new %s();\n", def.PHPName))
+		for _, f := range def.Fields {
+			if f.Kind == types.String {
+				sb.WriteString(fmt.Sprintf("        $dto->%s = $this->takeCString($data->%s->p, $data->%s->n);\n", f.PHPName, f.GoName, f.GoName))
+			} else {
+				sb.WriteString(fmt.Sprintf("        $dto->%s = $data->%s;\n", f.PHPName, f.GoName))
+			}
+		}
+		sb.WriteString("        return $dto;\n")
+		sb.WriteString("    }\n")
+	}
+
+	sb.WriteString(`
+    /**
+     * 将 PHP 字符串复制到临时 C 缓冲区并返回 char* 指针
+     * @param string $value
+     * @param array $buffers
+     * @return \FFI\CData
+     */
+    private function toCBuffer(string $value, array &$buffers): \FFI\CData {
+        $length = strlen($value);
+        $buffer = $this->ffi->new('char[' . ($length + 1) . ']');
+        if ($length > 0) {
+            \FFI::memcpy($buffer, $value, $length);
+        }
+        $buffers[] = $buffer;
+        return $this->ffi->cast('char *', \FFI::addr($buffer));
+    }
+`)
+	return sb.String()
+}
+
+// unboundComplexType 返回签名中第一个既不是 DTO 也无法直接传递的复杂类型
+func unboundComplexType(exp ExportedFunc) (Param, bool) {
+	for _, p := range append(append([]Param{}, exp.Params...), valueResults(exp)...) {
+		if p.Struct == nil && p.Handle == nil && isComplexType(p) {
+			return p, true
+		}
+	}
+	return Param{}, false
+}